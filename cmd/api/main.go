@@ -1,213 +1,357 @@
-package main
-
-import (
-	"context"
-	"log"
-	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
-
-	_ "github.com/swusjask/todo-api/docs" // This is required for swagger
-	"github.com/swusjask/todo-api/internal/auth"
-	"github.com/swusjask/todo-api/internal/config"
-	"github.com/swusjask/todo-api/internal/db"
-	"github.com/swusjask/todo-api/internal/handlers"
-	"github.com/swusjask/todo-api/internal/middleware"
-	"github.com/swusjask/todo-api/internal/repository"
-	"github.com/swusjask/todo-api/internal/service"
-)
-
-// @title Todo API
-// @version 1.0
-// @description A simple Todo REST API with CRUD operations and JWT authentication
-// @termsOfService http://swagger.io/terms/
-
-// @contact.name API Support
-// @contact.url http://www.swagger.io/support
-// @contact.email support@swagger.io
-
-// @license.name Apache 2.0
-// @license.url http://www.apache.org/licenses/LICENSE-2.0.html
-
-// @host localhost:8080
-// @BasePath /api/v1
-
-// @schemes http https
-
-// @securityDefinitions.apikey BearerAuth
-// @in header
-// @name Authorization
-// @description Type "Bearer" followed by a space and JWT token.
-func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatal("Failed to load config:", err)
-	}
-
-	// Connect to database
-	database, err := db.Connect(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
-	defer database.Close()
-
-	// Initialize auth components
-	jwtManager := auth.NewJWTManager(
-		cfg.JWTSecretKey,
-		cfg.JWTAccessTokenExpiry,
-		cfg.JWTRefreshTokenExpiry,
-	)
-	passwordManager := auth.NewPasswordManager(cfg.BcryptCost)
-
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(database)
-	todoRepo := repository.NewTodoRepository(database)
-
-	// Initialize services
-	authService := service.NewAuthService(userRepo, jwtManager, passwordManager)
-	todoService := service.NewTodoService(todoRepo)
-
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	todoHandler := handlers.NewTodoHandler(todoService)
-
-	// Setup router with auth middleware
-	router := setupRouter(cfg, authHandler, todoHandler, jwtManager)
-
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	// Start periodic cleanup of expired tokens
-	go func() {
-		ticker := time.NewTicker(24 * time.Hour) // Run once per day
-		defer ticker.Stop()
-
-		for range ticker.C {
-			if err := authService.CleanupExpiredTokens(context.Background()); err != nil {
-				log.Printf("Failed to cleanup expired tokens: %v", err)
-			}
-		}
-	}()
-
-	// Start server
-	go func() {
-		log.Printf("Starting server on port %s", cfg.Port)
-		log.Printf("Swagger docs available at http://localhost:%s/swagger/index.html", cfg.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("Failed to start server:", err)
-		}
-	}()
-
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
-
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
-	}
-
-	log.Println("Server exited")
-}
-
-func setupRouter(cfg *config.Config, authHandler *handlers.AuthHandler, todoHandler *handlers.TodoHandler, jwtManager *auth.JWTManager) *gin.Engine {
-	if cfg.Environment == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	router := gin.New()
-	router.Use(gin.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
-
-	// Health check endpoint (public)
-	// @Summary Health check
-	// @Description Check if the API is running
-	// @Tags health
-	// @Accept json
-	// @Produce json
-	// @Success 200 {object} map[string]string
-	// @Router /health [get]
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
-	})
-
-	// Swagger documentation (public)
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-	// API v1 routes
-	api := router.Group("/api/v1")
-	{
-		// Authentication routes (public)
-		authRoutes := api.Group("/auth")
-		{
-			authRoutes.POST("/register", authHandler.Register)
-			authRoutes.POST("/login", authHandler.Login)
-			authRoutes.POST("/refresh", authHandler.RefreshToken)
-
-			// Protected auth routes
-			authProtected := authRoutes.Group("")
-			authProtected.Use(middleware.AuthMiddleware(jwtManager))
-			{
-				authProtected.POST("/logout", authHandler.Logout)
-				authProtected.POST("/logout-all", authHandler.LogoutAll)
-				authProtected.GET("/me", authHandler.GetMe)
-				authProtected.GET("/health", authHandler.HealthCheck)
-			}
-		}
-
-		// Todo routes (protected)
-		todos := api.Group("/todos")
-		todos.Use(middleware.AuthMiddleware(jwtManager))
-		{
-			todos.POST("", todoHandler.Create)
-			todos.GET("", todoHandler.List)
-			todos.GET("/:id", todoHandler.Get)
-			todos.PUT("/:id", todoHandler.Update)
-			todos.DELETE("/:id", todoHandler.Delete)
-		}
-
-		// Optional: Public todo endpoints with optional auth
-		// This allows viewing todos without login but tracks the user if logged in
-		publicTodos := api.Group("/public/todos")
-		publicTodos.Use(middleware.OptionalAuth(jwtManager))
-		{
-			publicTodos.GET("", todoHandler.List)
-			publicTodos.GET("/:id", todoHandler.Get)
-		}
-
-		// Admin routes (example)
-		admin := api.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(jwtManager))
-		admin.Use(middleware.RequireAdmin())
-		{
-			// Add admin endpoints here
-			admin.GET("/stats", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Admin stats endpoint",
-					"note":    "Implement admin statistics here",
-				})
-			})
-		}
-	}
-
-	return router
-}
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Embed the timezone database so user timezones resolve on minimal images
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+
+	_ "github.com/swusjask/todo-api/docs" // This is required for swagger
+	"github.com/swusjask/todo-api/internal/auth"
+	"github.com/swusjask/todo-api/internal/config"
+	"github.com/swusjask/todo-api/internal/db"
+	"github.com/swusjask/todo-api/internal/handlers"
+	"github.com/swusjask/todo-api/internal/middleware"
+	"github.com/swusjask/todo-api/internal/pagination"
+	"github.com/swusjask/todo-api/internal/repository"
+	"github.com/swusjask/todo-api/internal/service"
+	"github.com/swusjask/todo-api/internal/storage"
+)
+
+// @title Todo API
+// @version 1.0
+// @description A simple Todo REST API with CRUD operations and JWT authentication
+// @termsOfService http://swagger.io/terms/
+
+// @contact.name API Support
+// @contact.url http://www.swagger.io/support
+// @contact.email support@swagger.io
+
+// @license.name Apache 2.0
+// @license.url http://www.apache.org/licenses/LICENSE-2.0.html
+
+// @host localhost:8080
+// @BasePath /api/v1
+
+// @schemes http https
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and JWT token.
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	// Connect to database
+	database, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	// Initialize auth components
+	jwtManager := auth.NewJWTManager(
+		cfg.JWTSecretKey,
+		cfg.JWTAccessTokenExpiry,
+		cfg.JWTRefreshTokenExpiry,
+	)
+	passwordManager := auth.NewPasswordManager(cfg.BcryptCost)
+	cursorCodec := pagination.NewCursorCodec(cfg.CursorSecretKey)
+
+	// Initialize blob storage for attachments
+	blobStore, err := storage.NewLocalStore(cfg.AttachmentsDir)
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(database)
+	todoRepo := repository.NewTodoRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	projectRepo := repository.NewProjectRepository(database)
+	commentRepo := repository.NewCommentRepository(database)
+	attachmentRepo := repository.NewAttachmentRepository(database)
+	shareRepo := repository.NewShareRepository(database)
+	shareLinkRepo := repository.NewShareLinkRepository(database)
+	workflowRepo := repository.NewWorkflowRepository(database)
+	timeEntryRepo := repository.NewTimeEntryRepository(database)
+
+	// Initialize services
+	authService := service.NewAuthService(userRepo, jwtManager, passwordManager)
+	todoService := service.NewTodoService(todoRepo, projectRepo, userRepo, shareRepo, workflowRepo, blobStore)
+	tagService := service.NewTagService(tagRepo)
+	projectService := service.NewProjectService(projectRepo)
+	commentService := service.NewCommentService(commentRepo, todoService)
+	attachmentService := service.NewAttachmentService(attachmentRepo, todoService, blobStore, cfg.AttachmentMaxSize, cfg.AttachmentAllowedTypes)
+	shareService := service.NewShareService(shareRepo, shareLinkRepo, todoRepo, projectRepo, userRepo, todoService, passwordManager)
+	workflowService := service.NewWorkflowService(workflowRepo, todoRepo, todoService)
+	timeEntryService := service.NewTimeEntryService(timeEntryRepo, todoService)
+
+	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(authService)
+	todoHandler := handlers.NewTodoHandler(todoService, cursorCodec)
+	tagHandler := handlers.NewTagHandler(tagService)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	shareHandler := handlers.NewShareHandler(shareService)
+	workflowHandler := handlers.NewWorkflowHandler(workflowService)
+	timeEntryHandler := handlers.NewTimeEntryHandler(timeEntryService)
+
+	// Setup router with auth middleware
+	router := setupRouter(cfg, authHandler, todoHandler, tagHandler, projectHandler, commentHandler, attachmentHandler, shareHandler, workflowHandler, timeEntryHandler, jwtManager)
+
+	// Create HTTP server
+	srv := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Start periodic cleanup of expired tokens
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour) // Run once per day
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := authService.CleanupExpiredTokens(context.Background()); err != nil {
+				log.Printf("Failed to cleanup expired tokens: %v", err)
+			}
+		}
+	}()
+
+	// Start periodic purge of todos whose trash retention has expired
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := todoService.PurgeTrash(context.Background(), cfg.TrashRetention)
+			if err != nil {
+				log.Printf("Failed to purge trashed todos: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("Purged %d trashed todos", purged)
+			}
+		}
+	}()
+
+	// Start periodic rebalancing of manually ordered lists whose position keys have grown long
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			rebalanced, err := todoService.RebalancePositions(context.Background())
+			if err != nil {
+				log.Printf("Failed to rebalance todo positions: %v", err)
+			}
+			if rebalanced > 0 {
+				log.Printf("Rebalanced positions in %d todo lists", rebalanced)
+			}
+		}
+	}()
+
+	// Start server
+	go func() {
+		log.Printf("Starting server on port %s", cfg.Port)
+		log.Printf("Swagger docs available at http://localhost:%s/swagger/index.html", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatal("Server forced to shutdown:", err)
+	}
+
+	log.Println("Server exited")
+}
+
+func setupRouter(cfg *config.Config, authHandler *handlers.AuthHandler, todoHandler *handlers.TodoHandler, tagHandler *handlers.TagHandler, projectHandler *handlers.ProjectHandler, commentHandler *handlers.CommentHandler, attachmentHandler *handlers.AttachmentHandler, shareHandler *handlers.ShareHandler, workflowHandler *handlers.WorkflowHandler, timeEntryHandler *handlers.TimeEntryHandler, jwtManager *auth.JWTManager) *gin.Engine {
+	if cfg.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.Logger())
+	router.Use(middleware.CORS())
+
+	// Health check endpoint (public)
+	// @Summary Health check
+	// @Description Check if the API is running
+	// @Tags health
+	// @Accept json
+	// @Produce json
+	// @Success 200 {object} map[string]string
+	// @Router /health [get]
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+
+	// Swagger documentation (public)
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// API v1 routes
+	api := router.Group("/api/v1")
+	{
+		// Authentication routes (public)
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.RefreshToken)
+
+			// Protected auth routes
+			authProtected := authRoutes.Group("")
+			authProtected.Use(middleware.AuthMiddleware(jwtManager))
+			{
+				authProtected.POST("/logout", authHandler.Logout)
+				authProtected.POST("/logout-all", authHandler.LogoutAll)
+				authProtected.GET("/me", authHandler.GetMe)
+				authProtected.PUT("/me/timezone", authHandler.UpdateTimezone)
+				authProtected.GET("/health", authHandler.HealthCheck)
+			}
+		}
+
+		// Todo routes (protected)
+		todos := api.Group("/todos")
+		todos.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			todos.POST("", todoHandler.Create)
+			todos.GET("", todoHandler.List)
+			todos.GET("/search", todoHandler.Search)
+			todos.GET("/trash", todoHandler.Trash)
+			todos.POST("/batch", todoHandler.Batch)
+			todos.GET("/:id", todoHandler.Get)
+			todos.GET("/:id/children", todoHandler.Children)
+			todos.GET("/:id/dependencies", todoHandler.Dependencies)
+			todos.POST("/:id/blocked-by", todoHandler.AddDependency)
+			todos.DELETE("/:id/blocked-by/:blocked_by_id", todoHandler.RemoveDependency)
+			todos.PUT("/:id/recurrence", todoHandler.SetRecurrence)
+			todos.DELETE("/:id/recurrence", todoHandler.StopRecurrence)
+			todos.GET("/:id/recurrence/preview", todoHandler.PreviewRecurrence)
+			todos.PUT("/:id", todoHandler.Update)
+			todos.PATCH("/:id", todoHandler.Patch)
+			todos.DELETE("/:id", todoHandler.Delete)
+			todos.POST("/:id/restore", todoHandler.Restore)
+			todos.POST("/:id/move", todoHandler.Move)
+			todos.GET("/:id/history", todoHandler.History)
+			todos.GET("/:id/history/:rev", todoHandler.Revision)
+			todos.POST("/:id/revert/:rev", todoHandler.Revert)
+			todos.GET("/:id/comments", commentHandler.List)
+			todos.POST("/:id/comments", commentHandler.Create)
+			todos.GET("/:id/attachments", attachmentHandler.List)
+			todos.POST("/:id/attachments", attachmentHandler.Upload)
+			todos.GET("/:id/attachments/:attachment_id", attachmentHandler.Download)
+			todos.DELETE("/:id/attachments/:attachment_id", attachmentHandler.Delete)
+			todos.GET("/:id/shares", shareHandler.ListTodoShares)
+			todos.POST("/:id/shares", shareHandler.ShareTodo)
+			todos.DELETE("/:id/shares/:user_id", shareHandler.RevokeTodoShare)
+			todos.GET("/:id/links", shareHandler.ListTodoLinks)
+			todos.POST("/:id/links", shareHandler.CreateTodoLink)
+			todos.DELETE("/:id/links/:link_id", shareHandler.RevokeTodoLink)
+			todos.POST("/:id/timer/start", timeEntryHandler.StartTimer)
+			todos.POST("/:id/timer/stop", timeEntryHandler.StopTimer)
+			todos.GET("/:id/time-entries", timeEntryHandler.ListByTodo)
+			todos.POST("/:id/time-entries", timeEntryHandler.Create)
+		}
+
+		// Tag routes (protected)
+		tags := api.Group("/tags")
+		tags.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			tags.POST("", tagHandler.Create)
+			tags.GET("", tagHandler.List)
+			tags.GET("/:id", tagHandler.Get)
+			tags.PUT("/:id", tagHandler.Update)
+			tags.DELETE("/:id", tagHandler.Delete)
+		}
+
+		// Project routes (protected)
+		projects := api.Group("/projects")
+		projects.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			projects.POST("", projectHandler.Create)
+			projects.GET("", projectHandler.List)
+			projects.GET("/:id", projectHandler.Get)
+			projects.PUT("/:id", projectHandler.Update)
+			projects.DELETE("/:id", projectHandler.Delete)
+			projects.GET("/:id/todos", todoHandler.ListByProject)
+			projects.GET("/:id/shares", shareHandler.ListProjectShares)
+			projects.POST("/:id/shares", shareHandler.ShareProject)
+			projects.DELETE("/:id/shares/:user_id", shareHandler.RevokeProjectShare)
+			projects.GET("/:id/links", shareHandler.ListProjectLinks)
+			projects.POST("/:id/links", shareHandler.CreateProjectLink)
+			projects.DELETE("/:id/links/:link_id", shareHandler.RevokeProjectLink)
+			projects.GET("/:id/workflow", workflowHandler.Get)
+			projects.PUT("/:id/workflow", workflowHandler.Replace)
+			projects.GET("/:id/board", workflowHandler.Board)
+		}
+
+		// Shared with me (protected)
+		api.GET("/shared-with-me", middleware.AuthMiddleware(jwtManager), shareHandler.SharedWithMe)
+
+		// Comment routes (protected)
+		comments := api.Group("/comments")
+		comments.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			comments.PUT("/:id", commentHandler.Update)
+			comments.DELETE("/:id", commentHandler.Delete)
+		}
+
+		// Time entry routes (protected)
+		timeEntries := api.Group("/time-entries")
+		timeEntries.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			timeEntries.GET("", timeEntryHandler.List)
+			timeEntries.GET("/totals", timeEntryHandler.Totals)
+			timeEntries.PUT("/:id", timeEntryHandler.Update)
+			timeEntries.DELETE("/:id", timeEntryHandler.Delete)
+		}
+
+		// Share links (public): read-only views of what a link's token grants access to
+		api.GET("/public/links/:token", shareHandler.OpenLink)
+
+		// Admin routes (example)
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(jwtManager))
+		admin.Use(middleware.RequireAdmin())
+		{
+			// Add admin endpoints here
+			admin.GET("/stats", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{
+					"message": "Admin stats endpoint",
+					"note":    "Implement admin statistics here",
+				})
+			})
+		}
+	}
+
+	return router
+}
@@ -36,6 +36,7 @@ type CustomClaims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	IsAdmin  bool   `json:"is_admin"`
+	Timezone string `json:"tz,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -46,6 +47,7 @@ func (j *JWTManager) GenerateAccessToken(user *models.User) (string, error) {
 		Email:    user.Email,
 		Username: user.Username,
 		IsAdmin:  user.IsAdmin,
+		Timezone: user.Timezone,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
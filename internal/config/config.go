@@ -1,140 +1,193 @@
-// internal/config/config.go
-package config
-
-import (
-	"fmt"
-	"log"
-	"os"
-	"strconv"
-	"time"
-
-	"github.com/joho/godotenv"
-)
-
-type Config struct {
-	Environment    string
-	Port           string
-	DatabaseURL    string
-	MigrationsPath string
-	LogLevel       string
-
-	// Database specifics parsed from DATABASE_URL
-	DBHost     string
-	DBPort     int
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
-
-	// JWT Configuration
-	JWTSecretKey          string
-	JWTAccessTokenExpiry  time.Duration
-	JWTRefreshTokenExpiry time.Duration
-
-	// Bcrypt Configuration
-	BcryptCost int
-}
-
-// Load reads configuration from environment variables
-func Load() (*Config, error) {
-	// Try to load .env file, but don't fail if it doesn't exist
-	// This allows the app to work with real environment variables in production
-	if err := godotenv.Load(); err != nil {
-		// Only log if the file exists but can't be read
-		if _, statErr := os.Stat(".env"); statErr == nil {
-			log.Printf("Warning: .env file exists but couldn't be loaded: %v", err)
-		}
-		// If file doesn't exist, that's fine - we'll use actual env vars
-	}
-
-	cfg := &Config{
-		Environment:    getEnv("ENVIRONMENT", "development"),
-		Port:           getEnv("PORT", "8080"),
-		DatabaseURL:    getEnv("DATABASE_URL", ""),
-		MigrationsPath: getEnv("MIGRATIONS_PATH", "migrations"),
-		LogLevel:       getEnv("LOG_LEVEL", "info"),
-
-		// JWT settings
-		JWTSecretKey: getEnv("JWT_SECRET_KEY", "your-super-secret-jwt-key-change-this-in-production"),
-
-		// Bcrypt settings
-		BcryptCost: getEnvAsInt("BCRYPT_COST", 10),
-	}
-
-	// Parse JWT token expiry durations
-	accessTokenExpiry, err := time.ParseDuration(getEnv("JWT_ACCESS_TOKEN_EXPIRY", "15m"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid JWT_ACCESS_TOKEN_EXPIRY: %w", err)
-	}
-	cfg.JWTAccessTokenExpiry = accessTokenExpiry
-
-	refreshTokenExpiry, err := time.ParseDuration(getEnv("JWT_REFRESH_TOKEN_EXPIRY", "168h")) // 7 days
-	if err != nil {
-		return nil, fmt.Errorf("invalid JWT_REFRESH_TOKEN_EXPIRY: %w", err)
-	}
-	cfg.JWTRefreshTokenExpiry = refreshTokenExpiry
-
-	// Validate required fields
-	if cfg.DatabaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is required")
-	}
-
-	// Validate JWT secret key in production
-	if cfg.Environment == "production" && cfg.JWTSecretKey == "your-super-secret-jwt-key-change-this-in-production" {
-		return nil, fmt.Errorf("JWT_SECRET_KEY must be changed in production")
-	}
-
-	// For convenience, also parse DATABASE_URL components
-	if err := cfg.parseDatabaseURL(); err != nil {
-		return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
-	}
-
-	return cfg, nil
-}
-
-// LoadFromFile explicitly loads configuration from a specific .env file
-// This is useful for testing or when you have multiple env files
-func LoadFromFile(filename string) (*Config, error) {
-	if err := godotenv.Load(filename); err != nil {
-		return nil, fmt.Errorf("failed to load env file %s: %w", filename, err)
-	}
-
-	return Load()
-}
-
-// getEnv gets an environment variable with a fallback default value
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
-
-// getEnvAsInt gets an environment variable as an integer with a fallback default value
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := getEnv(key, "")
-	if valueStr == "" {
-		return defaultValue
-	}
-
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
-	}
-
-	return value
-}
-
-// parseDatabaseURL extracts components from a postgres connection string
-func (c *Config) parseDatabaseURL() error {
-	// In a real application, you'd use a proper URL parser
-	// For now, we'll trust that DATABASE_URL is properly formatted
-	c.DBHost = getEnv("DB_HOST", "localhost")
-	c.DBPort, _ = strconv.Atoi(getEnv("DB_PORT", "5432"))
-	c.DBUser = getEnv("DB_USER", "postgres")
-	c.DBPassword = getEnv("DB_PASSWORD", "")
-	c.DBName = getEnv("DB_NAME", "todos")
-	c.DBSSLMode = getEnv("DB_SSLMODE", "disable")
-
-	return nil
-}
+// internal/config/config.go
+package config
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// cursorKeyLabel is the HKDF info the default cursor key is derived from the JWT key under
+const cursorKeyLabel = "todo-api pagination cursor signing key v1"
+
+type Config struct {
+	Environment    string
+	Port           string
+	DatabaseURL    string
+	MigrationsPath string
+	LogLevel       string
+
+	// Database specifics parsed from DATABASE_URL
+	DBHost     string
+	DBPort     int
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+
+	// JWT Configuration
+	JWTSecretKey          string
+	JWTAccessTokenExpiry  time.Duration
+	JWTRefreshTokenExpiry time.Duration
+
+	// How long deleted todos stay in the trash before they are purged
+	TrashRetention time.Duration
+
+	// Attachment storage and upload limits
+	AttachmentsDir         string
+	AttachmentMaxSize      int64    // in bytes
+	AttachmentAllowedTypes []string // MIME types; "image/*" allows a whole family
+
+	// Pagination cursor signing key (derived from JWTSecretKey when unset)
+	CursorSecretKey string
+
+	// Bcrypt Configuration
+	BcryptCost int
+}
+
+// Load reads configuration from environment variables
+func Load() (*Config, error) {
+	// Try to load .env file, but don't fail if it doesn't exist
+	// This allows the app to work with real environment variables in production
+	if err := godotenv.Load(); err != nil {
+		// Only log if the file exists but can't be read
+		if _, statErr := os.Stat(".env"); statErr == nil {
+			log.Printf("Warning: .env file exists but couldn't be loaded: %v", err)
+		}
+		// If file doesn't exist, that's fine - we'll use actual env vars
+	}
+
+	cfg := &Config{
+		Environment:    getEnv("ENVIRONMENT", "development"),
+		Port:           getEnv("PORT", "8080"),
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+		MigrationsPath: getEnv("MIGRATIONS_PATH", "migrations"),
+		LogLevel:       getEnv("LOG_LEVEL", "info"),
+
+		// JWT settings
+		JWTSecretKey: getEnv("JWT_SECRET_KEY", "your-super-secret-jwt-key-change-this-in-production"),
+
+		// Cursor settings
+		CursorSecretKey: getEnv("CURSOR_SECRET_KEY", ""),
+
+		// Bcrypt settings
+		BcryptCost: getEnvAsInt("BCRYPT_COST", 10),
+
+		// Attachment settings
+		AttachmentsDir:    getEnv("ATTACHMENTS_DIR", "data/attachments"),
+		AttachmentMaxSize: int64(getEnvAsInt("ATTACHMENT_MAX_SIZE", 10<<20)), // 10 MiB
+	}
+
+	// Parse JWT token expiry durations
+	accessTokenExpiry, err := time.ParseDuration(getEnv("JWT_ACCESS_TOKEN_EXPIRY", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TOKEN_EXPIRY: %w", err)
+	}
+	cfg.JWTAccessTokenExpiry = accessTokenExpiry
+
+	refreshTokenExpiry, err := time.ParseDuration(getEnv("JWT_REFRESH_TOKEN_EXPIRY", "168h")) // 7 days
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TOKEN_EXPIRY: %w", err)
+	}
+	cfg.JWTRefreshTokenExpiry = refreshTokenExpiry
+
+	trashRetention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "720h")) // 30 days
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION: %w", err)
+	}
+	if trashRetention <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION must be positive")
+	}
+	cfg.TrashRetention = trashRetention
+
+	if cfg.AttachmentMaxSize <= 0 {
+		return nil, fmt.Errorf("ATTACHMENT_MAX_SIZE must be positive")
+	}
+	for _, mimeType := range strings.Split(getEnv("ATTACHMENT_ALLOWED_TYPES", "image/*,application/pdf,text/plain"), ",") {
+		if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); mimeType != "" {
+			cfg.AttachmentAllowedTypes = append(cfg.AttachmentAllowedTypes, mimeType)
+		}
+	}
+
+	// Without a key of their own, cursors are signed with one derived from the JWT key,
+	// so a cursor signature can never pass for a token signature or the other way round
+	if cfg.CursorSecretKey == "" {
+		key, err := hkdf.Key(sha256.New, []byte(cfg.JWTSecretKey), nil, cursorKeyLabel, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive cursor secret key: %w", err)
+		}
+		cfg.CursorSecretKey = hex.EncodeToString(key)
+	}
+
+	// Validate required fields
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required")
+	}
+
+	// Validate JWT secret key in production
+	if cfg.Environment == "production" && cfg.JWTSecretKey == "your-super-secret-jwt-key-change-this-in-production" {
+		return nil, fmt.Errorf("JWT_SECRET_KEY must be changed in production")
+	}
+
+	// For convenience, also parse DATABASE_URL components
+	if err := cfg.parseDatabaseURL(); err != nil {
+		return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadFromFile explicitly loads configuration from a specific .env file
+// This is useful for testing or when you have multiple env files
+func LoadFromFile(filename string) (*Config, error) {
+	if err := godotenv.Load(filename); err != nil {
+		return nil, fmt.Errorf("failed to load env file %s: %w", filename, err)
+	}
+
+	return Load()
+}
+
+// getEnv gets an environment variable with a fallback default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsInt gets an environment variable as an integer with a fallback default value
+func getEnvAsInt(key string, defaultValue int) int {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// parseDatabaseURL extracts components from a postgres connection string
+func (c *Config) parseDatabaseURL() error {
+	// In a real application, you'd use a proper URL parser
+	// For now, we'll trust that DATABASE_URL is properly formatted
+	c.DBHost = getEnv("DB_HOST", "localhost")
+	c.DBPort, _ = strconv.Atoi(getEnv("DB_PORT", "5432"))
+	c.DBUser = getEnv("DB_USER", "postgres")
+	c.DBPassword = getEnv("DB_PASSWORD", "")
+	c.DBName = getEnv("DB_NAME", "todos")
+	c.DBSSLMode = getEnv("DB_SSLMODE", "disable")
+
+	return nil
+}
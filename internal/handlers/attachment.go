@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// multipartOverhead is the room allowed on top of the file size for multipart headers and boundaries
+const multipartOverhead = 64 << 10
+
+// AttachmentHandler handles HTTP requests for files attached to todos
+type AttachmentHandler struct {
+	service *service.AttachmentService
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(service *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// List handles GET /todos/:id/attachments
+// @Summary List a todo's attachments
+// @Description Get the metadata of the files attached to a todo, oldest first
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {array} models.Attachment "Attachments of the todo"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	attachments, err := h.service.List(c.Request.Context(), todoID)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to list attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Upload handles POST /todos/:id/attachments
+// @Summary Attach a file to a todo
+// @Description Upload a file as the "file" field of a multipart form. The content type is detected from the content and must be one of the configured types
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} models.Attachment "Successfully attached file"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 415 {object} ErrorResponse "File type not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	// Stream the file part straight into blob storage rather than buffering the form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxSize()+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Missing file field"})
+			return
+		}
+		if err != nil {
+			respondAttachmentError(c, err, "Invalid request body")
+			return
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.service.Upload(c.Request.Context(), todoID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			respondAttachmentError(c, err, "Failed to upload attachment")
+			return
+		}
+
+		c.JSON(http.StatusCreated, attachment)
+		return
+	}
+}
+
+// Download handles GET /todos/:id/attachments/:attachment_id
+// @Summary Download an attachment
+// @Description Stream the content of an attachment. Range requests are supported
+// @Tags attachments
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param Range header string false "Byte range to download"
+// @Success 200 {file} file "Attachment content"
+// @Success 206 {file} file "Requested range of the attachment content"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo or attachment not found"
+// @Failure 416 {string} string "Range not satisfiable"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	todoID, id, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.Open(c.Request.Context(), todoID, id)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, attachment.Filename, attachment.CreatedAt, content)
+}
+
+// Delete handles DELETE /todos/:id/attachments/:attachment_id
+// @Summary Delete an attachment
+// @Tags attachments
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 204 "Attachment successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo or attachment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	todoID, id, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), todoID, id); err != nil {
+		respondAttachmentError(c, err, "Failed to delete attachment")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// parseAttachmentParams reads the todo and attachment IDs from the path, responding with 400 when either is malformed
+func parseAttachmentParams(c *gin.Context) (int, int, bool) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid attachment ID format"})
+		return 0, 0, false
+	}
+
+	return todoID, id, true
+}
+
+// respondAttachmentError maps attachment service errors to HTTP responses
+func respondAttachmentError(c *gin.Context, err error, fallback string) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Attachment not found"})
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrAttachmentTooLarge), errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "File too large"})
+	case errors.Is(err, service.ErrUnsupportedMediaType):
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -222,6 +222,49 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, userResponse)
 }
 
+// UpdateTimezone handles changing the current user's timezone
+// @Summary Update timezone
+// @Description Set the IANA timezone used to evaluate date-relative todo filters such as due_today.
+// @Description Access tokens carry the timezone, so refresh the token to apply the change
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param timezone body models.UpdateTimezoneRequest true "New timezone"
+// @Success 200 {object} models.UserResponse "Updated user information"
+// @Failure 400 {object} ErrorResponse "Invalid request or unknown timezone"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/me/timezone [put]
+func (h *AuthHandler) UpdateTimezone(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req models.UpdateTimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userResponse, err := h.authService.UpdateTimezone(c.Request.Context(), user.ID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update timezone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse)
+}
+
 // HealthCheck is a simple authenticated endpoint for testing
 // @Summary Health check for authenticated routes
 // @Description Check if authentication is working
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// CommentHandler handles HTTP requests for comments on todos
+type CommentHandler struct {
+	service *service.CommentService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(service *service.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+// PaginatedCommentsResponse represents a page of comments
+type PaginatedCommentsResponse struct {
+	Data       []*models.Comment `json:"data"`
+	Pagination PaginationMeta    `json:"pagination"`
+}
+
+// List handles GET /todos/:id/comments
+// @Summary List a todo's comments
+// @Description Get the comments on a todo, oldest first
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} PaginatedCommentsResponse "Comments with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/comments [get]
+func (h *CommentHandler) List(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	comments, totalCount, err := h.service.List(c.Request.Context(), todoID, page, pageSize)
+	if err != nil {
+		respondCommentError(c, err, "Failed to list comments")
+		return
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, PaginatedCommentsResponse{
+		Data: comments,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// Create handles POST /todos/:id/comments
+// @Summary Comment on a todo
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param comment body models.CreateCommentRequest true "Comment to post"
+// @Success 201 {object} models.Comment "Successfully posted comment"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Commenter access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/comments [post]
+func (h *CommentHandler) Create(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.service.Create(c.Request.Context(), todoID, &req)
+	if err != nil {
+		respondCommentError(c, err, "Failed to post comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// Update handles PUT /comments/:id
+// @Summary Edit a comment
+// @Description Replace the body of one of your own comments; edited_at records when it was changed
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Param comment body models.UpdateCommentRequest true "New comment body"
+// @Success 200 {object} models.Comment "Successfully edited comment"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not the author of the comment"
+// @Failure 404 {object} ErrorResponse "Comment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /comments/{id} [put]
+func (h *CommentHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondCommentError(c, err, "Failed to edit comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// Delete handles DELETE /comments/:id
+// @Summary Delete a comment
+// @Description Delete one of your own comments; admins can delete any comment
+// @Tags comments
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Success 204 "Comment successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not the author of the comment"
+// @Failure 404 {object} ErrorResponse "Comment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		respondCommentError(c, err, "Failed to delete comment")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// respondCommentError maps comment service errors to HTTP responses
+func respondCommentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Comment not found"})
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// todoETag renders a strong entity tag for a todo representation: the todo's version,
+// which conditional writes compare, followed by a hash of the rendered body, which catches
+// changes to derived fields such as comment counts, tags, positions or nested subtasks
+func todoETag(version int, body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%d-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// respondTodo writes a todo with its ETag
+// With revalidate set, a request whose If-None-Match names that ETag gets 304 Not Modified instead
+func respondTodo(c *gin.Context, status int, todo *models.Todo, revalidate bool) {
+	body, err := json.Marshal(todo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render todo"})
+		return
+	}
+
+	etag := todoETag(todo.Version, body)
+	c.Header("ETag", etag)
+	if revalidate && ifNoneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", body)
+}
+
+// parseIfMatch reads the versions listed in the If-Match header
+// It returns nil when the header is absent or "*", so the write applies unconditionally.
+// Weak and malformed tags can never match, leaving an empty, non-nil list
+func parseIfMatch(c *gin.Context) []int {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil
+		}
+
+		// If-Match uses strong comparison, so weak tags (W/"...") are skipped
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil || !strings.HasPrefix(tag, `"`) {
+			continue
+		}
+		// Only the version part counts; writes don't depend on the derived fields
+		unquoted, _, _ = strings.Cut(unquoted, "-")
+		if version, err := strconv.Atoi(unquoted); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+// ifNoneMatch reports whether the If-None-Match header matches the given ETag,
+// meaning the client's cached copy is current
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-None-Match uses weak comparison, so W/ prefixes are ignored
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
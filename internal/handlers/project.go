@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// ProjectHandler handles HTTP requests for the current user's projects
+type ProjectHandler struct {
+	service *service.ProjectService
+}
+
+// NewProjectHandler creates a new project handler
+func NewProjectHandler(service *service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
+// Create handles POST /projects
+// @Summary Create a project
+// @Description Create a project, optionally nested inside another project via parent_id
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project body models.CreateProjectRequest true "Project to create"
+// @Success 201 {object} models.Project "Successfully created project"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects [post]
+func (h *ProjectHandler) Create(c *gin.Context) {
+	var req models.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	project, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondProjectError(c, err, "Failed to create project")
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// List handles GET /projects
+// @Summary List projects
+// @Description List the current user's projects alphabetically as a flat list; use parent_id to build the folder tree
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param include_archived query bool false "Also return archived projects"
+// @Success 200 {array} models.Project "The user's projects"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects [get]
+func (h *ProjectHandler) List(c *gin.Context) {
+	projects, err := h.service.List(c.Request.Context(), queryBool(c, "include_archived"))
+	if err != nil {
+		respondProjectError(c, err, "Failed to list projects")
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// Get handles GET /projects/:id
+// @Summary Get a project by ID
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project "Project found"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id} [get]
+func (h *ProjectHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	project, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondProjectError(c, err, "Failed to get project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// Update handles PUT /projects/:id
+// @Summary Update a project
+// @Description Rename, recolor, archive or move a project. parent_id 0 moves it to the top level
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param project body models.UpdateProjectRequest true "Project fields to update"
+// @Success 200 {object} models.Project "Successfully updated project"
+// @Failure 400 {object} ErrorResponse "Invalid request, e.g. moving a project inside itself"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id} [put]
+func (h *ProjectHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	project, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondProjectError(c, err, "Failed to update project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// Delete handles DELETE /projects/:id
+// @Summary Delete a project
+// @Description mode=move (default) moves the project's todos to the inbox and its subprojects up one level
+// @Description mode=cascade deletes the project, all its subprojects and every todo in them
+// @Tags projects
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param mode query string false "What happens to the project's contents" Enums(move, cascade)
+// @Success 204 "Project successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format or mode"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id} [delete]
+func (h *ProjectHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	mode := models.ProjectDeleteMode(c.Query("mode"))
+	if err := h.service.Delete(c.Request.Context(), id, mode); err != nil {
+		respondProjectError(c, err, "Failed to delete project")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// respondProjectError maps project service errors to HTTP responses
+func respondProjectError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// ShareHandler handles HTTP requests for sharing todos and projects with other users
+// and through share links
+type ShareHandler struct {
+	service *service.ShareService
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(service *service.ShareService) *ShareHandler {
+	return &ShareHandler{service: service}
+}
+
+// ShareTodo handles POST /todos/:id/shares
+// @Summary Share a todo
+// @Description Give another user, identified by user_id or email, a role on a todo you own: viewer, commenter or editor
+// @Description Sharing with a user who already has a share changes their role
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param share body models.ShareRequest true "User to share with and their role"
+// @Success 201 {object} models.Share "Successfully shared todo"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can share it"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/shares [post]
+func (h *ShareHandler) ShareTodo(c *gin.Context) {
+	h.share(c, models.ShareKindTodo)
+}
+
+// ListTodoShares handles GET /todos/:id/shares
+// @Summary List a todo's shares
+// @Description Get the users a todo is shared with directly, with their roles
+// @Tags shares
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {array} models.Share "Shares of the todo"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/shares [get]
+func (h *ShareHandler) ListTodoShares(c *gin.Context) {
+	h.list(c, models.ShareKindTodo)
+}
+
+// RevokeTodoShare handles DELETE /todos/:id/shares/:user_id
+// @Summary Revoke a todo share
+// @Description Remove a user's access to a todo you own, or give up your own access
+// @Tags shares
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param user_id path int true "ID of the user the todo is shared with"
+// @Success 204 "Share successfully revoked"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can revoke other users' shares"
+// @Failure 404 {object} ErrorResponse "Todo or share not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/shares/{user_id} [delete]
+func (h *ShareHandler) RevokeTodoShare(c *gin.Context) {
+	h.revoke(c, models.ShareKindTodo)
+}
+
+// ShareProject handles POST /projects/:id/shares
+// @Summary Share a project
+// @Description Give another user, identified by user_id or email, a role on every todo filed directly in a project you own
+// @Description Editors can also file new todos in the project. Sharing with a user who already has a share changes their role
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param share body models.ShareRequest true "User to share with and their role"
+// @Success 201 {object} models.Share "Successfully shared project"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the project's owner can share it"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/shares [post]
+func (h *ShareHandler) ShareProject(c *gin.Context) {
+	h.share(c, models.ShareKindProject)
+}
+
+// ListProjectShares handles GET /projects/:id/shares
+// @Summary List a project's shares
+// @Description Get the users a project is shared with, with their roles
+// @Tags shares
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {array} models.Share "Shares of the project"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/shares [get]
+func (h *ShareHandler) ListProjectShares(c *gin.Context) {
+	h.list(c, models.ShareKindProject)
+}
+
+// RevokeProjectShare handles DELETE /projects/:id/shares/:user_id
+// @Summary Revoke a project share
+// @Description Remove a user's access to a project you own, or give up your own access
+// @Tags shares
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param user_id path int true "ID of the user the project is shared with"
+// @Success 204 "Share successfully revoked"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the project's owner can revoke other users' shares"
+// @Failure 404 {object} ErrorResponse "Project or share not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/shares/{user_id} [delete]
+func (h *ShareHandler) RevokeProjectShare(c *gin.Context) {
+	h.revoke(c, models.ShareKindProject)
+}
+
+// SharedWithMe handles GET /shared-with-me
+// @Summary List what is shared with me
+// @Description Get the todos and projects other users have shared with you, with the role you were given on each
+// @Tags shares
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SharedWithMe "Shared todos and projects"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /shared-with-me [get]
+func (h *ShareHandler) SharedWithMe(c *gin.Context) {
+	shared, err := h.service.SharedWithMe(c.Request.Context())
+	if err != nil {
+		respondShareError(c, err, "Failed to list shared items")
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// share serves the invite endpoints of todos and projects
+func (h *ShareHandler) share(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	share, err := h.service.Share(c.Request.Context(), kind, targetID, &req)
+	if err != nil {
+		respondShareError(c, err, "Failed to share")
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+// list serves the share listings of todos and projects
+func (h *ShareHandler) list(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	shares, err := h.service.List(c.Request.Context(), kind, targetID)
+	if err != nil {
+		respondShareError(c, err, "Failed to list shares")
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// revoke serves the revoke endpoints of todos and projects
+func (h *ShareHandler) revoke(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID format"})
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), kind, targetID, userID); err != nil {
+		respondShareError(c, err, "Failed to revoke share")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// respondShareError maps share service errors to HTTP responses
+func respondShareError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrShareNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share not found"})
+	case errors.Is(err, service.ErrShareLinkNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share link not found"})
+	case errors.Is(err, service.ErrShareLinkExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Error: "Share link has expired"})
+	case errors.Is(err, service.ErrShareLinkPassword):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Share link password is missing or incorrect"})
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
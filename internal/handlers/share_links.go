@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// CreateTodoLink handles POST /todos/:id/links
+// @Summary Create a todo share link
+// @Description Create a link that gives anyone who has it read-only access to a todo you own and its subtasks
+// @Description The token is only returned in this response. Links can expire and be protected with a password
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param link body models.ShareLinkRequest false "Link options"
+// @Success 201 {object} models.ShareLink "Successfully created share link"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can create links"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/links [post]
+func (h *ShareHandler) CreateTodoLink(c *gin.Context) {
+	h.createLink(c, models.ShareKindTodo)
+}
+
+// ListTodoLinks handles GET /todos/:id/links
+// @Summary List a todo's share links
+// @Description Get the share links of a todo you own, with how often each was viewed
+// @Tags share-links
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {array} models.ShareLink "Share links of the todo"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can list links"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/links [get]
+func (h *ShareHandler) ListTodoLinks(c *gin.Context) {
+	h.listLinks(c, models.ShareKindTodo)
+}
+
+// RevokeTodoLink handles DELETE /todos/:id/links/:link_id
+// @Summary Revoke a todo share link
+// @Description Delete a share link of a todo you own; the link stops working immediately
+// @Tags share-links
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param link_id path int true "Share link ID"
+// @Success 204 "Share link successfully revoked"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can revoke links"
+// @Failure 404 {object} ErrorResponse "Todo or share link not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/links/{link_id} [delete]
+func (h *ShareHandler) RevokeTodoLink(c *gin.Context) {
+	h.revokeLink(c, models.ShareKindTodo)
+}
+
+// CreateProjectLink handles POST /projects/:id/links
+// @Summary Create a project share link
+// @Description Create a link that gives anyone who has it read-only access to a project you own and its top-level todos
+// @Description The token is only returned in this response. Links can expire and be protected with a password
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param link body models.ShareLinkRequest false "Link options"
+// @Success 201 {object} models.ShareLink "Successfully created share link"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the project's owner can create links"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/links [post]
+func (h *ShareHandler) CreateProjectLink(c *gin.Context) {
+	h.createLink(c, models.ShareKindProject)
+}
+
+// ListProjectLinks handles GET /projects/:id/links
+// @Summary List a project's share links
+// @Description Get the share links of a project you own, with how often each was viewed
+// @Tags share-links
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {array} models.ShareLink "Share links of the project"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the project's owner can list links"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/links [get]
+func (h *ShareHandler) ListProjectLinks(c *gin.Context) {
+	h.listLinks(c, models.ShareKindProject)
+}
+
+// RevokeProjectLink handles DELETE /projects/:id/links/:link_id
+// @Summary Revoke a project share link
+// @Description Delete a share link of a project you own; the link stops working immediately
+// @Tags share-links
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param link_id path int true "Share link ID"
+// @Success 204 "Share link successfully revoked"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the project's owner can revoke links"
+// @Failure 404 {object} ErrorResponse "Project or share link not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/links/{link_id} [delete]
+func (h *ShareHandler) RevokeProjectLink(c *gin.Context) {
+	h.revokeLink(c, models.ShareKindProject)
+}
+
+// OpenLink handles GET /public/links/:token
+// @Summary Open a share link
+// @Description Get the read-only view a share link grants: a todo with its subtasks, or a project with a page of its top-level todos
+// @Description No account is needed. Password-protected links need the password in the X-Share-Password header
+// @Tags share-links
+// @Produce json
+// @Param token path string true "Share link token"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Param page query int false "Page number of a project's todos (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} models.PublicShare "What the link shares"
+// @Failure 401 {object} ErrorResponse "Password missing or incorrect"
+// @Failure 404 {object} ErrorResponse "Share link not found"
+// @Failure 410 {object} ErrorResponse "Share link has expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /public/links/{token} [get]
+func (h *ShareHandler) OpenLink(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	view, err := h.service.OpenLink(c.Request.Context(), c.Param("token"), c.GetHeader("X-Share-Password"), page, pageSize)
+	if err != nil {
+		respondShareError(c, err, "Failed to open share link")
+		return
+	}
+
+	// Views are counted and links can be revoked, so nothing in between may serve a copy
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// createLink serves the create link endpoints of todos and projects
+func (h *ShareHandler) createLink(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	// Every option is optional, so an empty body asks for a plain link
+	var req models.ShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request body",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	link, err := h.service.CreateLink(c.Request.Context(), kind, targetID, &req)
+	if err != nil {
+		respondShareError(c, err, "Failed to create share link")
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// listLinks serves the link listings of todos and projects
+func (h *ShareHandler) listLinks(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	links, err := h.service.ListLinks(c.Request.Context(), kind, targetID)
+	if err != nil {
+		respondShareError(c, err, "Failed to list share links")
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// revokeLink serves the revoke link endpoints of todos and projects
+func (h *ShareHandler) revokeLink(c *gin.Context, kind models.ShareKind) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	linkID, err := strconv.Atoi(c.Param("link_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid link ID format"})
+		return
+	}
+
+	if err := h.service.RevokeLink(c.Request.Context(), kind, targetID, linkID); err != nil {
+		respondShareError(c, err, "Failed to revoke share link")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// TagHandler handles HTTP requests for the current user's tags
+type TagHandler struct {
+	service *service.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(service *service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// Create handles POST /tags
+// @Summary Create a tag
+// @Description Create a tag for the current user. Names are unique per user, ignoring case
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tag body models.CreateTagRequest true "Tag to create"
+// @Success 201 {object} models.Tag "Successfully created tag"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Tag already exists"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags [post]
+func (h *TagHandler) Create(c *gin.Context) {
+	var req models.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondTagError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// List handles GET /tags
+// @Summary List tags
+// @Description List the current user's tags alphabetically, with the number of todos carrying each
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Tag "The user's tags"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags [get]
+func (h *TagHandler) List(c *gin.Context) {
+	tags, err := h.service.List(c.Request.Context())
+	if err != nil {
+		respondTagError(c, err, "Failed to list tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Get handles GET /tags/:id
+// @Summary Get a tag by ID
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Success 200 {object} models.Tag "Tag found"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{id} [get]
+func (h *TagHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	tag, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondTagError(c, err, "Failed to get tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Update handles PUT /tags/:id
+// @Summary Update a tag
+// @Description Rename or recolor a tag. Todos carrying the tag pick up the change
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Param tag body models.UpdateTagRequest true "Tag fields to update"
+// @Success 200 {object} models.Tag "Successfully updated tag"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Tag not found"
+// @Failure 409 {object} ErrorResponse "Another tag already has this name"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{id} [put]
+func (h *TagHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondTagError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete handles DELETE /tags/:id
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every todo carrying it
+// @Tags tags
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Success 204 "Tag successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{id} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		respondTagError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// respondTagError maps tag service errors to HTTP responses
+func respondTagError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Tag not found"})
+	case errors.Is(err, service.ErrTagExists):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Tag already exists"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// TimeEntryHandler handles HTTP requests for time tracking on todos
+type TimeEntryHandler struct {
+	service *service.TimeEntryService
+}
+
+// NewTimeEntryHandler creates a new time entry handler
+func NewTimeEntryHandler(service *service.TimeEntryService) *TimeEntryHandler {
+	return &TimeEntryHandler{service: service}
+}
+
+// PaginatedTimeEntriesResponse represents a page of time entries
+type PaginatedTimeEntriesResponse struct {
+	Data       []*models.TimeEntry `json:"data"`
+	Pagination PaginationMeta      `json:"pagination"`
+}
+
+// StartTimer handles POST /todos/:id/timer/start
+// @Summary Start a timer on a todo
+// @Description Start tracking time on a todo. Each user has at most one running timer:
+// @Description a timer already running, on this or any other todo, is stopped at the same instant
+// @Tags time tracking
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 201 {object} models.TimeEntry "The running timer"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/timer/start [post]
+func (h *TimeEntryHandler) StartTimer(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	entry, err := h.service.StartTimer(c.Request.Context(), todoID)
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to start timer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// StopTimer handles POST /todos/:id/timer/stop
+// @Summary Stop the timer on a todo
+// @Description Stop the timer you have running on a todo, turning it into a finished time entry
+// @Tags time tracking
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Success 200 {object} models.TimeEntry "The stopped time entry"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 409 {object} ErrorResponse "No timer of yours is running on the todo"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/timer/stop [post]
+func (h *TimeEntryHandler) StopTimer(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	entry, err := h.service.StopTimer(c.Request.Context(), todoID)
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to stop timer")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ListByTodo handles GET /todos/:id/time-entries
+// @Summary List a todo's time entries
+// @Description Get everyone's time entries on a todo, most recently started first
+// @Tags time tracking
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} PaginatedTimeEntriesResponse "Time entries with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/time-entries [get]
+func (h *TimeEntryHandler) ListByTodo(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	entries, totalCount, err := h.service.ListByTodo(c.Request.Context(), todoID, page, pageSize)
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to list time entries")
+		return
+	}
+
+	respondTimeEntries(c, entries, totalCount, page, pageSize)
+}
+
+// Create handles POST /todos/:id/time-entries
+// @Summary Log time on a todo
+// @Description Record time spent on a todo by hand, e.g. after forgetting to start a timer
+// @Tags time tracking
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo ID"
+// @Param entry body models.CreateTimeEntryRequest true "Time entry to log"
+// @Success 201 {object} models.TimeEntry "Successfully logged time entry"
+// @Failure 400 {object} ErrorResponse "Invalid request body, or the entry ends before it starts or in the future"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/time-entries [post]
+func (h *TimeEntryHandler) Create(c *gin.Context) {
+	todoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.CreateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.service.Create(c.Request.Context(), todoID, &req)
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to log time entry")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// List handles GET /time-entries
+// @Summary List your time entries
+// @Description Get your time entries across todos, most recently started first. from and to bound when entries started
+// @Tags time tracking
+// @Produce json
+// @Security BearerAuth
+// @Param todo_id query int false "Only entries on this todo"
+// @Param project_id query string false "Only entries on todos in this project, or inbox for todos without a project"
+// @Param from query string false "Only entries started at or after this time"
+// @Param to query string false "Only entries started before this time"
+// @Param running query bool false "Only the running timer (true) or only finished entries (false)"
+// @Param all_users query bool false "List every user's entries; admins, or the owner of the project given in project_id"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} PaginatedTimeEntriesResponse "Time entries with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin outside a project they own"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /time-entries [get]
+func (h *TimeEntryHandler) List(c *gin.Context) {
+	filter, err := parseTimeEntryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid query parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	entries, totalCount, err := h.service.List(c.Request.Context(), filter, page, pageSize, queryBool(c, "all_users"))
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to list time entries")
+		return
+	}
+
+	respondTimeEntries(c, entries, totalCount, page, pageSize)
+}
+
+// Totals handles GET /time-entries/totals
+// @Summary Total your tracked time
+// @Description Sum your time entries by todo, project or day, in seconds. Running timers count up to now
+// @Description An entry counts towards the day it started on, in tz or your own timezone
+// @Tags time tracking
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string true "What to total by" Enums(todo, project, day)
+// @Param todo_id query int false "Only entries on this todo"
+// @Param project_id query string false "Only entries on todos in this project, or inbox for todos without a project"
+// @Param from query string false "Only entries started at or after this time"
+// @Param to query string false "Only entries started before this time"
+// @Param tz query string false "IANA timezone days are counted in, e.g. Europe/Berlin"
+// @Param all_users query bool false "Total every user's entries; admins, or the owner of the project given in project_id"
+// @Success 200 {object} models.TimeTotals "Time totals"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter, or a timezone days can't be counted in"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin outside a project they own"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /time-entries/totals [get]
+func (h *TimeEntryHandler) Totals(c *gin.Context) {
+	filter, err := parseTimeEntryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid query parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	groupBy := models.TimeGrouping(c.Query("group_by"))
+	totals, err := h.service.Totals(c.Request.Context(), filter, groupBy, c.Query("tz"), queryBool(c, "all_users"))
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to total time entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, totals)
+}
+
+// Update handles PUT /time-entries/:id
+// @Summary Edit a time entry
+// @Description Replace the start, end and note of one of your time entries
+// @Description Leave out ended_at to keep a running timer running; finished entries can't be restarted
+// @Tags time tracking
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Time entry ID"
+// @Param entry body models.UpdateTimeEntryRequest true "New times and note"
+// @Success 200 {object} models.TimeEntry "Successfully edited time entry"
+// @Failure 400 {object} ErrorResponse "Invalid request, or the entry ends before it starts or in the future"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your time entry"
+// @Failure 404 {object} ErrorResponse "Time entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /time-entries/{id} [put]
+func (h *TimeEntryHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.UpdateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondTimeEntryError(c, err, "Failed to edit time entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// Delete handles DELETE /time-entries/:id
+// @Summary Delete a time entry
+// @Description Delete one of your time entries, or a running timer without recording it; admins can delete any entry
+// @Tags time tracking
+// @Security BearerAuth
+// @Param id path int true "Time entry ID"
+// @Success 204 "Time entry successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your time entry"
+// @Failure 404 {object} ErrorResponse "Time entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /time-entries/{id} [delete]
+func (h *TimeEntryHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		respondTimeEntryError(c, err, "Failed to delete time entry")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// parseTimeEntryFilter reads the time entry filter query parameters
+func parseTimeEntryFilter(c *gin.Context) (*models.TimeEntryFilter, error) {
+	filter := &models.TimeEntryFilter{}
+
+	if raw := c.Query("todo_id"); raw != "" {
+		todoID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("todo_id must be a todo ID")
+		}
+		filter.TodoID = &todoID
+	}
+
+	if raw := c.Query("project_id"); raw == "inbox" {
+		filter.Inbox = true
+	} else if raw != "" {
+		projectID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("project_id must be a project ID or inbox")
+		}
+		filter.ProjectID = &projectID
+	}
+
+	timeParams := []struct {
+		key    string
+		target **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	}
+	for _, p := range timeParams {
+		raw := c.Query(p.key)
+		if raw == "" {
+			continue
+		}
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", p.key)
+		}
+		*p.target = &t
+	}
+
+	if raw := c.Query("running"); raw != "" {
+		running, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("running must be true or false")
+		}
+		filter.Running = &running
+	}
+
+	return filter, nil
+}
+
+// respondTimeEntries writes a page of time entries with its pagination metadata
+func respondTimeEntries(c *gin.Context, entries []*models.TimeEntry, totalCount, page, pageSize int) {
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, PaginatedTimeEntriesResponse{
+		Data: entries,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// respondTimeEntryError maps time entry service errors to HTTP responses
+func respondTimeEntryError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTimeEntryNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Time entry not found"})
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+	case errors.Is(err, service.ErrTimerNotRunning):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -1,221 +1,731 @@
-package handlers
-
-import (
-	"errors"
-	"net/http"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-	"github.com/swusjask/todo-api/internal/models"
-	"github.com/swusjask/todo-api/internal/service"
-)
-
-// TodoHandler handles HTTP requests for todos
-type TodoHandler struct {
-	service *service.TodoService
-}
-
-func NewTodoHandler(service *service.TodoService) *TodoHandler {
-	return &TodoHandler{service: service}
-}
-
-// Create handles POST /todos
-// @Summary Create a new todo
-// @Description Create a new todo item with title and description
-// @Tags todos
-// @Accept json
-// @Produce json
-// @Param todo body models.CreateTodoRequest true "Todo object to create"
-// @Success 201 {object} models.Todo "Successfully created todo"
-// @Failure 400 {object} ErrorResponse "Invalid request body"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /todos [post]
-func (h *TodoHandler) Create(c *gin.Context) {
-	var req models.CreateTodoRequest
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request body",
-			Details: err.Error(),
-		})
-		return
-	}
-
-	todo, err := h.service.Create(c.Request.Context(), &req)
-	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create todo"})
-		return
-	}
-
-	c.JSON(http.StatusCreated, todo)
-}
-
-// Get handles GET /todos/:id
-// @Summary Get a todo by ID
-// @Description Get a single todo item by its ID
-// @Tags todos
-// @Accept json
-// @Produce json
-// @Param id path int true "Todo ID"
-// @Success 200 {object} models.Todo "Todo found"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 404 {object} ErrorResponse "Todo not found"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /todos/{id} [get]
-func (h *TodoHandler) Get(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
-		return
-	}
-
-	todo, err := h.service.GetByID(c.Request.Context(), id)
-	if err != nil {
-		if errors.Is(err, service.ErrTodoNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get todo"})
-		return
-	}
-
-	c.JSON(http.StatusOK, todo)
-}
-
-// List handles GET /todos with pagination
-// @Summary List todos
-// @Description Get a paginated list of todos
-// @Tags todos
-// @Accept json
-// @Produce json
-// @Param page query int false "Page number (default: 1)"
-// @Param page_size query int false "Page size (default: 20, max: 100)"
-// @Success 200 {object} PaginatedTodosResponse "List of todos with pagination"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /todos [get]
-func (h *TodoHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-
-	todos, totalCount, err := h.service.List(c.Request.Context(), page, pageSize)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list todos"})
-		return
-	}
-
-	totalPages := (totalCount + pageSize - 1) / pageSize
-
-	c.JSON(http.StatusOK, PaginatedTodosResponse{
-		Data: todos,
-		Pagination: PaginationMeta{
-			Page:       page,
-			PageSize:   pageSize,
-			TotalCount: totalCount,
-			TotalPages: totalPages,
-		},
-	})
-}
-
-// Update handles PUT /todos/:id
-// @Summary Update a todo
-// @Description Update an existing todo's title, description, or completion status
-// @Tags todos
-// @Accept json
-// @Produce json
-// @Param id path int true "Todo ID"
-// @Param todo body models.UpdateTodoRequest true "Todo fields to update"
-// @Success 200 {object} models.Todo "Successfully updated todo"
-// @Failure 400 {object} ErrorResponse "Invalid request"
-// @Failure 404 {object} ErrorResponse "Todo not found"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /todos/{id} [put]
-func (h *TodoHandler) Update(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
-		return
-	}
-
-	var req models.UpdateTodoRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid request body",
-			Details: err.Error(),
-		})
-		return
-	}
-
-	todo, err := h.service.Update(c.Request.Context(), id, &req)
-	if err != nil {
-		if errors.Is(err, service.ErrTodoNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
-			return
-		}
-		if errors.Is(err, service.ErrInvalidInput) {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update todo"})
-		return
-	}
-
-	c.JSON(http.StatusOK, todo)
-}
-
-// Delete handles DELETE /todos/:id
-// @Summary Delete a todo
-// @Description Delete a todo by its ID
-// @Tags todos
-// @Accept json
-// @Produce json
-// @Param id path int true "Todo ID"
-// @Success 204 "Todo successfully deleted"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 404 {object} ErrorResponse "Todo not found"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /todos/{id} [delete]
-func (h *TodoHandler) Delete(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
-		return
-	}
-
-	err = h.service.Delete(c.Request.Context(), id)
-	if err != nil {
-		if errors.Is(err, service.ErrTodoNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete todo"})
-		return
-	}
-
-	c.JSON(http.StatusNoContent, nil)
-}
-
-// Response types for Swagger documentation
-
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error" example:"Invalid request body"`
-	Details string `json:"details,omitempty" example:"title is required"`
-}
-
-// PaginatedTodosResponse represents a paginated list of todos
-type PaginatedTodosResponse struct {
-	Data       []*models.Todo `json:"data"`
-	Pagination PaginationMeta `json:"pagination"`
-}
-
-// PaginationMeta contains pagination metadata
-type PaginationMeta struct {
-	Page       int `json:"page" example:"1"`
-	PageSize   int `json:"page_size" example:"20"`
-	TotalCount int `json:"total_count" example:"100"`
-	TotalPages int `json:"total_pages" example:"5"`
-}
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/pagination"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// TodoHandler handles HTTP requests for todos
+type TodoHandler struct {
+	service *service.TodoService
+	cursors *pagination.CursorCodec
+}
+
+func NewTodoHandler(service *service.TodoService, cursors *pagination.CursorCodec) *TodoHandler {
+	return &TodoHandler{service: service, cursors: cursors}
+}
+
+// Create handles POST /todos
+// @Summary Create a new todo
+// @Description Create a new todo item with title, description, and optional start and due dates, priority and tags
+// @Description Tags that don't exist yet are created
+// @Description assignee_id assigns the todo to another user, who can then see and update it
+// @Description recurrence makes the todo repeat according to an RRULE, counted from due_at
+// @Description status_id places the todo in a non-terminal state of its project's workflow; it defaults to the first one
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param todo body models.CreateTodoRequest true "Todo object to create"
+// @Success 201 {object} models.Todo "Successfully created todo"
+// @Header 201 {string} ETag "Version and content hash of the created todo"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 403 {object} ErrorResponse "Filing the todo in a project shared without editor access"
+// @Failure 409 {object} ErrorResponse "The workflow state is at its WIP limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos [post]
+func (h *TodoHandler) Create(c *gin.Context) {
+	var req models.CreateTodoRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	todo, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrWIPLimitReached) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create todo"})
+		return
+	}
+
+	respondTodo(c, http.StatusCreated, todo, false)
+}
+
+// Get handles GET /todos/:id
+// @Summary Get a todo by ID
+// @Description Get a single todo item by its ID. The ETag header carries the todo's version and a hash of the response;
+// @Description send it back in If-None-Match to get 304 Not Modified while the response is unchanged, or in If-Match to update the todo
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param all_users query bool false "Admins only: look up todos of any user"
+// @Param include query string false "subtasks to nest the todo's subtask tree"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} models.Todo "Todo found"
+// @Header 200 {string} ETag "Version and content hash of the todo"
+// @Success 304 "Cached copy is still current"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id} [get]
+func (h *TodoHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	todo, err := h.service.GetByID(c.Request.Context(), id, queryBool(c, "all_users"))
+	if err != nil {
+		if errors.Is(err, service.ErrTodoNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get todo"})
+		return
+	}
+
+	if wantsSubtasks(c) {
+		if err := h.service.ExpandSubtasks(c.Request.Context(), todo); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get todo"})
+			return
+		}
+	}
+
+	respondTodo(c, http.StatusOK, todo, true)
+}
+
+// Children handles GET /todos/:id/children
+// @Summary List a todo's subtasks
+// @Description Get the direct subtasks of a todo, oldest first. include=subtasks expands each of them into its own subtask tree
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param include query string false "subtasks to nest the full subtask tree"
+// @Param all_users query bool false "Admins only: look up todos of any user"
+// @Success 200 {array} models.Todo "Direct subtasks"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/children [get]
+func (h *TodoHandler) Children(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	children, err := h.service.ListChildren(c.Request.Context(), id, queryBool(c, "all_users"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTodoNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list subtasks"})
+		}
+		return
+	}
+
+	if wantsSubtasks(c) {
+		if err := h.service.ExpandSubtasks(c.Request.Context(), children...); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list subtasks"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, children)
+}
+
+// List handles GET /todos with pagination
+// @Summary List todos
+// @Description Get a paginated, filterable list of the current user's todos
+// @Description Timestamps accept RFC 3339 (2024-01-15T15:04:05Z) or plain dates (2024-01-15)
+// @Description Passing cursor (empty for the first page) switches to keyset pagination ordered by -created_at:
+// @Description follow pagination.next_cursor until it is absent. Page numbers and total counts are omitted in that mode unless include_total is set
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; empty to start keyset pagination"
+// @Param include_total query bool false "Cursor mode only: also return total_count"
+// @Param all_users query bool false "Admins only: list todos of every user"
+// @Param completed query bool false "Only completed (true) or open (false) todos"
+// @Param created_after query string false "Created at or after this time"
+// @Param created_before query string false "Created before this time"
+// @Param updated_after query string false "Updated at or after this time"
+// @Param updated_before query string false "Updated before this time"
+// @Param completed_after query string false "Completed at or after this time"
+// @Param completed_before query string false "Completed before this time"
+// @Param created_by query int false "Creator user ID"
+// @Param due_after query string false "Due at or after this time"
+// @Param due_before query string false "Due before this time"
+// @Param overdue query bool false "Only open todos whose due date has passed"
+// @Param due_today query bool false "Only todos due today in the user's timezone"
+// @Param due_within query string false "Only todos due between now and now plus this duration, e.g. 7d or 12h"
+// @Param priority query string false "Comma-separated priorities to include, e.g. high,urgent"
+// @Param project_id query string false "Only todos in this project, or inbox for todos without a project"
+// @Param parent_id query string false "Only subtasks of this todo, or none for top-level todos"
+// @Param is_blocked query bool false "Only todos that are (true) or are not (false) blocked by open todos"
+// @Param assignee query string false "Only todos assigned to me, to this user ID, or to none"
+// @Param status_id query int false "Only todos in this workflow state"
+// @Param include query string false "subtasks to nest each todo's subtask tree; combine with parent_id=none to avoid listing subtasks twice"
+// @Param tag query []string false "Only todos carrying all of these tags (repeatable or comma-separated)" collectionFormat(multi)
+// @Param tag_any query []string false "Only todos carrying at least one of these tags (repeatable or comma-separated)" collectionFormat(multi)
+// @Param tz query string false "IANA timezone overriding the user's for due_today, e.g. Europe/Berlin"
+// @Param q query string false "Case-insensitive substring of title or description"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (default: -created_at). Fields: id, title, created_at, updated_at, completed_at, due_at, start_at, priority, position. Priority ties are ordered by earliest due date, so -priority lists the most urgent work first; position is the manual order set with POST /todos/{id}/move"
+// @Success 200 {object} PaginatedTodosResponse "List of todos with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid query parameter"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos [get]
+func (h *TodoHandler) List(c *gin.Context) {
+	h.listTodos(c, nil)
+}
+
+// ListByProject handles GET /projects/:id/todos
+// @Summary List a project's todos
+// @Description Get the todos filed directly under a project. Accepts the same filtering, sorting and pagination parameters as GET /todos
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; empty to start keyset pagination"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (default: -created_at)"
+// @Success 200 {object} PaginatedTodosResponse "List of todos with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid ID format or query parameter"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/todos [get]
+func (h *TodoHandler) ListByProject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	h.listTodos(c, &id)
+}
+
+// listTodos serves the todo listings, optionally restricted to one project
+func (h *TodoHandler) listTodos(c *gin.Context, projectID *int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	filter, err := parseTodoFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid query parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+	if projectID != nil {
+		filter.ProjectID = projectID
+		filter.Inbox = false
+	}
+
+	sort := models.ParseSort(c.Query("sort"))
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.listByCursor(c, filter, sort, cursor, pageSize)
+		return
+	}
+
+	todos, totalCount, err := h.service.List(c.Request.Context(), filter, sort, page, pageSize, queryBool(c, "all_users"))
+	if err != nil {
+		h.respondListError(c, err)
+		return
+	}
+
+	if wantsSubtasks(c) {
+		if err := h.service.ExpandSubtasks(c.Request.Context(), todos...); err != nil {
+			h.respondListError(c, err)
+			return
+		}
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, PaginatedTodosResponse{
+		Data: todos,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// Search handles GET /todos/search
+// @Summary Search todos
+// @Description Full-text search over todo titles and descriptions with stemming, ranked by relevance
+// @Description Supports web search syntax: "quoted phrases", OR, and -excluded words. List filters can be combined with q
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param all_users query bool false "Admins only: search todos of every user"
+// @Param completed query bool false "Only completed (true) or open (false) todos"
+// @Param created_by query int false "Creator user ID"
+// @Success 200 {object} SearchTodosResponse "Ranked search results with pagination"
+// @Failure 400 {object} ErrorResponse "Missing or invalid query"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/search [get]
+func (h *TodoHandler) Search(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	filter, err := parseTodoFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid query parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+	// q is the full-text query here, not the substring filter of GET /todos
+	filter.Query = ""
+
+	results, totalCount, err := h.service.Search(c.Request.Context(), c.Query("q"), filter, page, pageSize, queryBool(c, "all_users"))
+	if err != nil {
+		h.respondListError(c, err)
+		return
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, SearchTodosResponse{
+		Data: results,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// listByCursor serves the keyset pagination mode of GET /todos
+func (h *TodoHandler) listByCursor(c *gin.Context, filter *models.TodoFilter, sort []models.SortField, cursor string, pageSize int) {
+	// Keyset pagination only works over the (created_at, id) order the cursor encodes
+	if len(sort) > 0 && !(len(sort) == 1 && sort[0] == models.DefaultTodoSort[0]) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid query parameter",
+			Details: "cursor pagination only supports sort=-created_at",
+		})
+		return
+	}
+
+	var after *models.TodoCursor
+	if cursor != "" {
+		after = &models.TodoCursor{}
+		if err := h.cursors.Decode(cursor, after); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid query parameter",
+				Details: "cursor is invalid or has been tampered with",
+			})
+			return
+		}
+	}
+
+	todos, next, totalCount, err := h.service.ListAfter(c.Request.Context(), filter, after, pageSize, queryBool(c, "include_total"), queryBool(c, "all_users"))
+	if err != nil {
+		h.respondListError(c, err)
+		return
+	}
+
+	if wantsSubtasks(c) {
+		if err := h.service.ExpandSubtasks(c.Request.Context(), todos...); err != nil {
+			h.respondListError(c, err)
+			return
+		}
+	}
+
+	meta := PaginationMeta{
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+	}
+	if next != nil {
+		meta.NextCursor, err = h.cursors.Encode(next)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list todos"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, PaginatedTodosResponse{
+		Data:       todos,
+		Pagination: meta,
+	})
+}
+
+// respondListError maps service errors of the list endpoints to HTTP responses
+func (h *TodoHandler) respondListError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list todos"})
+	}
+}
+
+// Update handles PUT /todos/:id
+// @Summary Replace a todo
+// @Description Replace a todo's title, description, completion status, start and due dates, priority, tags, project, parent, assignee
+// @Description and workflow state with the given representation. Omitted or null optional fields are cleared; use PATCH to change single fields
+// @Description In a project with a workflow, status_id may only follow its transitions; entering a terminal state completes the todo,
+// @Description and completing or reopening it without a state moves it to the first terminal or non-terminal state
+// @Description Set complete_subtasks to also complete every open subtask when the todo becomes completed
+// @Description A todo blocked by open todos can only be completed with force=true
+// @Description Completing a recurring todo creates its next occurrence, returned as next_occurrence
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param todo body models.ReplaceTodoRequest true "Full todo representation"
+// @Param If-Match header string false "ETag the todo must still have for the update to apply"
+// @Success 200 {object} models.Todo "Successfully updated todo"
+// @Header 200 {string} ETag "Version and content hash of the updated todo"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 403 {object} ErrorResponse "Editor access is required; reassigning is limited to the creator and the assignee, moving to another project to the todo's owners"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 409 {object} ErrorResponse "Completing a todo that is blocked by open todos without force, or the new state is at its WIP limit"
+// @Failure 412 {object} ErrorResponse "The todo was modified since the If-Match ETag was issued"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id} [put]
+func (h *TodoHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.ReplaceTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	req.IfMatch = parseIfMatch(c)
+
+	todo, err := h.service.Replace(c.Request.Context(), id, &req)
+	if err != nil {
+		respondUpdateError(c, err)
+		return
+	}
+
+	respondTodo(c, http.StatusOK, todo, false)
+}
+
+// respondUpdateError maps the errors of PUT and PATCH to HTTP responses
+func respondUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrTodoBlocked), errors.Is(err, service.ErrPatchTestFailed), errors.Is(err, service.ErrWIPLimitReached):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrVersionMismatch):
+		c.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update todo"})
+	}
+}
+
+// Delete handles DELETE /todos/:id
+// @Summary Delete a todo
+// @Description Move a todo and all of its subtasks to the trash. Trashed todos can be restored until they are purged
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the todo must still have for the delete to apply"
+// @Success 204 "Todo successfully deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "Only the todo's owners can delete it"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 412 {object} ErrorResponse "The todo was modified since the If-Match ETag was issued"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id} [delete]
+func (h *TodoHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	err = h.service.Delete(c.Request.Context(), id, parseIfMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrTodoNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			c.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete todo"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// parseTodoFilter reads the list filters from the query string
+// Absent parameters leave the corresponding filter unset
+func parseTodoFilter(c *gin.Context) (*models.TodoFilter, error) {
+	filter := &models.TodoFilter{
+		Query:    c.Query("q"),
+		Overdue:  queryBool(c, "overdue"),
+		DueToday: queryBool(c, "due_today"),
+		Timezone: c.Query("tz"),
+	}
+
+	if raw := c.Query("completed"); raw != "" {
+		completed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("completed must be true or false")
+		}
+		filter.Completed = &completed
+	}
+
+	if raw := c.Query("created_by"); raw != "" {
+		createdBy, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("created_by must be a user ID")
+		}
+		filter.CreatedBy = &createdBy
+	}
+
+	timeParams := []struct {
+		key    string
+		target **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+		{"completed_after", &filter.CompletedAfter},
+		{"completed_before", &filter.CompletedBefore},
+		{"due_after", &filter.DueAfter},
+		{"due_before", &filter.DueBefore},
+	}
+	for _, p := range timeParams {
+		raw := c.Query(p.key)
+		if raw == "" {
+			continue
+		}
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", p.key)
+		}
+		*p.target = &t
+	}
+
+	if raw := c.Query("priority"); raw != "" {
+		for _, priority := range strings.Split(raw, ",") {
+			filter.Priorities = append(filter.Priorities, models.Priority(strings.TrimSpace(priority)))
+		}
+	}
+
+	if raw := c.Query("project_id"); raw == "inbox" {
+		filter.Inbox = true
+	} else if raw != "" {
+		projectID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("project_id must be a project ID or inbox")
+		}
+		filter.ProjectID = &projectID
+	}
+
+	if raw := c.Query("parent_id"); raw == "none" {
+		filter.TopLevel = true
+	} else if raw != "" {
+		parentID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("parent_id must be a todo ID or none")
+		}
+		filter.ParentID = &parentID
+	}
+
+	switch raw := c.Query("assignee"); raw {
+	case "":
+	case "me":
+		filter.AssignedToMe = true
+	case "none":
+		filter.Unassigned = true
+	default:
+		assigneeID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("assignee must be me, none or a user ID")
+		}
+		filter.AssigneeID = &assigneeID
+	}
+
+	if raw := c.Query("status_id"); raw != "" {
+		statusID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("status_id must be a workflow state ID")
+		}
+		filter.StatusID = &statusID
+	}
+
+	if raw := c.Query("is_blocked"); raw != "" {
+		blocked, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("is_blocked must be true or false")
+		}
+		filter.IsBlocked = &blocked
+	}
+
+	filter.Tags = queryList(c, "tag")
+	filter.TagsAny = queryList(c, "tag_any")
+
+	if raw := c.Query("due_within"); raw != "" {
+		within, err := parseDurationParam(raw)
+		if err != nil || within <= 0 {
+			return nil, errors.New("due_within must be a positive duration such as 7d or 12h")
+		}
+		filter.DueWithin = within
+	}
+
+	return filter, nil
+}
+
+// parseDurationParam accepts Go durations (12h, 90m) plus whole days (7d)
+func parseDurationParam(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// parseTimeParam accepts either a full RFC 3339 timestamp or a plain date (midnight UTC)
+func parseTimeParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// queryList reads a query parameter that may be repeated and/or comma-separated
+// (?tag=a&tag=b and ?tag=a,b are equivalent), skipping empty entries
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// wantsSubtasks reports whether the include parameter asks for the subtask tree
+func wantsSubtasks(c *gin.Context) bool {
+	for _, include := range queryList(c, "include") {
+		if include == "subtasks" {
+			return true
+		}
+	}
+	return false
+}
+
+// queryBool reads an optional boolean query parameter, treating anything unparsable as false
+func queryBool(c *gin.Context, key string) bool {
+	value, _ := strconv.ParseBool(c.Query(key))
+	return value
+}
+
+// Response types for Swagger documentation
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error" example:"Invalid request body"`
+	Details string `json:"details,omitempty" example:"title is required"`
+}
+
+// PaginatedTodosResponse represents a paginated list of todos
+type PaginatedTodosResponse struct {
+	Data       []*models.Todo `json:"data"`
+	Pagination PaginationMeta `json:"pagination"`
+}
+
+// SearchTodosResponse represents a page of full-text search results
+type SearchTodosResponse struct {
+	Data       []*models.TodoSearchResult `json:"data"`
+	Pagination PaginationMeta             `json:"pagination"`
+}
+
+// PaginationMeta contains pagination metadata
+// Page-based listings always fill page, total_count and total_pages;
+// cursor-based listings fill next_cursor and only include total_count on request
+type PaginationMeta struct {
+	Page       int    `json:"page,omitempty" example:"1"`
+	PageSize   int    `json:"page_size" example:"20"`
+	TotalCount *int   `json:"total_count,omitempty" example:"100"`
+	TotalPages *int   `json:"total_pages,omitempty" example:"5"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJjIjoiMjAyNC0wMS0xNVQxNTowNDowNVoiLCJpIjo0Mn0.c2lnbmF0dXJl"`
+}
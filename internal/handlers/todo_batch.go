@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// BatchResponse reports the outcome of every operation of a batch
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded" example:"3"`
+	Failed    int               `json:"failed" example:"0"`
+}
+
+// BatchItemResult is the outcome of one batch operation, with the status code
+// the equivalent single-todo request would have returned
+type BatchItemResult struct {
+	Index  int            `json:"index" example:"0"`
+	Op     models.BatchOp `json:"op" example:"complete"`
+	ID     int            `json:"id,omitempty" example:"42"`
+	Status int            `json:"status" example:"200"`
+	Todo   *models.Todo   `json:"todo,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Batch handles POST /todos/batch
+// @Summary Run todo operations in bulk
+// @Description Create, update, delete or complete up to 100 todos in a single transaction.
+// @Description By default the batch is all-or-nothing: the first failing operation rolls everything back and its error is returned.
+// @Description With continue_on_error the operations that succeed are committed and every operation reports its own status
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchRequest true "Operations to run"
+// @Success 200 {object} BatchResponse "Per-operation results"
+// @Failure 400 {object} ErrorResponse "Invalid request, or an invalid operation rolled the batch back"
+// @Failure 404 {object} ErrorResponse "An operation referenced a missing todo and the batch was rolled back"
+// @Failure 409 {object} ErrorResponse "An operation tried to complete a blocked todo or exceed a WIP limit and the batch was rolled back"
+// @Failure 412 {object} ErrorResponse "An operation's version no longer matched and the batch was rolled back"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/batch [post]
+func (h *TodoHandler) Batch(c *gin.Context) {
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results, err := h.service.Batch(c.Request.Context(), &req)
+	if err != nil {
+		var batchErr *service.BatchError
+		if errors.As(err, &batchErr) {
+			status, message := batchErrorStatus(batchErr.Err)
+			c.JSON(status, ErrorResponse{Error: "Batch rolled back", Details: fmt.Sprintf("operation %d: %s", batchErr.Index, message)})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to run batch"})
+		return
+	}
+
+	response := BatchResponse{Results: make([]BatchItemResult, 0, len(results))}
+	for _, result := range results {
+		item := BatchItemResult{Index: result.Index, Op: result.Op, ID: result.ID, Todo: result.Todo}
+
+		switch {
+		case result.Err != nil:
+			item.Status, item.Error = batchErrorStatus(result.Err)
+			response.Failed++
+		case result.Op == models.BatchCreate:
+			item.Status = http.StatusCreated
+			response.Succeeded++
+		case result.Op == models.BatchDelete:
+			item.Status = http.StatusNoContent
+			response.Succeeded++
+		default:
+			item.Status = http.StatusOK
+			response.Succeeded++
+		}
+
+		response.Results = append(response.Results, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// batchErrorStatus maps the error of a batch operation to the status code and message
+// the equivalent single-todo request would have responded with
+func batchErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		return http.StatusNotFound, "Todo not found"
+	case errors.Is(err, service.ErrInvalidInput):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrTodoBlocked), errors.Is(err, service.ErrWIPLimitReached):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, service.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, err.Error()
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden, err.Error()
+	default:
+		return http.StatusInternalServerError, "Operation failed"
+	}
+}
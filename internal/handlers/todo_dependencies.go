@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// Dependencies handles GET /todos/:id/dependencies
+// @Summary List a todo's dependencies
+// @Description Get the todos this todo is blocked by and the todos it blocks, open ones first
+// @Tags todos
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param all_users query bool false "Admins only: look up todos of any user"
+// @Success 200 {object} models.TodoDependencies "Blocking and blocked todos"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/dependencies [get]
+func (h *TodoHandler) Dependencies(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	deps, err := h.service.Dependencies(c.Request.Context(), id, queryBool(c, "all_users"))
+	if err != nil {
+		respondDependencyError(c, err, "Failed to list dependencies")
+		return
+	}
+
+	c.JSON(http.StatusOK, deps)
+}
+
+// AddDependency handles POST /todos/:id/blocked-by
+// @Summary Mark a todo as blocked by another
+// @Description Declare that the todo cannot be completed until blocked_by_id is. Dependencies that would form a cycle are rejected
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param dependency body models.AddDependencyRequest true "The blocking todo"
+// @Success 201 {object} models.TodoDependencies "Updated dependencies of the todo"
+// @Failure 400 {object} ErrorResponse "Invalid request, unknown blocker or cycle"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/blocked-by [post]
+func (h *TodoHandler) AddDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	deps, err := h.service.AddDependency(c.Request.Context(), id, &req)
+	if err != nil {
+		respondDependencyError(c, err, "Failed to add dependency")
+		return
+	}
+
+	c.JSON(http.StatusCreated, deps)
+}
+
+// RemoveDependency handles DELETE /todos/:id/blocked-by/:blocked_by_id
+// @Summary Remove a dependency
+// @Description Declare that the todo is no longer blocked by blocked_by_id
+// @Tags todos
+// @Param id path int true "Todo ID"
+// @Param blocked_by_id path int true "Blocking todo ID"
+// @Success 204 "Dependency successfully removed"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo or dependency not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/blocked-by/{blocked_by_id} [delete]
+func (h *TodoHandler) RemoveDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+	blockedByID, err := strconv.Atoi(c.Param("blocked_by_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.RemoveDependency(c.Request.Context(), id, blockedByID); err != nil {
+		respondDependencyError(c, err, "Failed to remove dependency")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// respondDependencyError maps dependency service errors to HTTP responses
+func respondDependencyError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrDependencyNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Dependency not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
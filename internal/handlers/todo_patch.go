@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// Patch handles PATCH /todos/:id
+// @Summary Patch a todo
+// @Description Change single fields of a todo. The patch is applied to the todo's full representation (see PUT):
+// @Description application/merge-patch+json (RFC 7396) sets the given members and clears those set to null;
+// @Description application/json-patch+json (RFC 6902) applies a list of operations, and a failing test operation aborts the whole patch
+// @Tags todos
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param patch body object true "Merge patch object or JSON Patch operation list"
+// @Param force query bool false "Complete the todo even while its blockers are open"
+// @Param complete_subtasks query bool false "Also complete every open subtask when the todo becomes completed"
+// @Param If-Match header string false "ETag the todo must still have for the patch to apply"
+// @Success 200 {object} models.Todo "Successfully patched todo"
+// @Header 200 {string} ETag "Version and content hash of the patched todo"
+// @Failure 400 {object} ErrorResponse "Invalid patch, or the patched todo is invalid"
+// @Failure 403 {object} ErrorResponse "Editor access is required; reassigning is limited to the creator and the assignee, moving to another project to the todo's owners"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 409 {object} ErrorResponse "A test operation failed, completing a blocked todo without force, or the new state is at its WIP limit"
+// @Failure 412 {object} ErrorResponse "The todo was modified since the If-Match ETag was issued"
+// @Failure 415 {object} ErrorResponse "Unsupported patch content type"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id} [patch]
+func (h *TodoHandler) Patch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	format := models.PatchFormat(c.ContentType())
+	if format != models.MergePatch && format != models.JSONPatch {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+			Error:   "Unsupported content type",
+			Details: "use application/merge-patch+json or application/json-patch+json",
+		})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Details: err.Error()})
+		return
+	}
+
+	todo, err := h.service.Patch(c.Request.Context(), id, &models.PatchTodoRequest{
+		Format:           format,
+		Patch:            patch,
+		Force:            queryBool(c, "force"),
+		CompleteSubtasks: queryBool(c, "complete_subtasks"),
+		IfMatch:          parseIfMatch(c),
+	})
+	if err != nil {
+		respondUpdateError(c, err)
+		return
+	}
+
+	respondTodo(c, http.StatusOK, todo, false)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// Move handles POST /todos/:id/move
+// @Summary Reorder a todo
+// @Description Place a todo right before one todo (before) or right after another (after), or between the two.
+// @Description Anchors must be in the same list: the same project, or the inbox, at the same subtask level.
+// @Description Only the moved todo changes; list with sort=position to see the manual order
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param move body models.MoveTodoRequest true "Where to place the todo"
+// @Success 200 {object} models.Todo "Todo with its new position"
+// @Failure 400 {object} ErrorResponse "Invalid request, or anchors outside the todo's list"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/move [post]
+func (h *TodoHandler) Move(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.MoveTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	todo, err := h.service.Move(c.Request.Context(), id, &req)
+	if err != nil {
+		respondMoveError(c, err, "Failed to move todo")
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// respondMoveError maps move errors to HTTP responses
+func respondMoveError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// SetRecurrence handles PUT /todos/:id/recurrence
+// @Summary Make a todo repeat or edit its series
+// @Description Set an RFC 5545 RRULE (without DTSTART) on an open todo with a due date. Occurrences are counted from the
+// @Description todo's current due date in the given timezone (default: the user's), so local times survive DST changes.
+// @Description Completing the todo then creates the next occurrence and moves the rule to it
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param recurrence body models.RecurrenceRequest true "Recurrence rule"
+// @Success 200 {object} models.Todo "Todo with its recurrence"
+// @Failure 400 {object} ErrorResponse "Invalid rule or timezone, or todo without a due date"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/recurrence [put]
+func (h *TodoHandler) SetRecurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.RecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	todo, err := h.service.SetRecurrence(c.Request.Context(), id, &req)
+	if err != nil {
+		respondRecurrenceError(c, err, "Failed to set recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// StopRecurrence handles DELETE /todos/:id/recurrence
+// @Summary Stop a recurring series
+// @Description Remove the recurrence rule; the todo stays, but completing it no longer creates a next occurrence
+// @Tags todos
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} models.Todo "Todo without recurrence"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/recurrence [delete]
+func (h *TodoHandler) StopRecurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	todo, err := h.service.StopRecurrence(c.Request.Context(), id)
+	if err != nil {
+		respondRecurrenceError(c, err, "Failed to stop recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// PreviewRecurrence handles GET /todos/:id/recurrence/preview
+// @Summary Preview upcoming occurrences
+// @Description List the due dates of the next occurrences after the todo's current due date
+// @Tags todos
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param count query int false "Number of occurrences (default: 5, max: 100)"
+// @Param all_users query bool false "Admins only: look up todos of any user"
+// @Success 200 {object} models.RecurrencePreview "Upcoming occurrences"
+// @Failure 400 {object} ErrorResponse "Invalid count or todo does not repeat"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/recurrence/preview [get]
+func (h *TodoHandler) PreviewRecurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid query parameter", Details: "count must be a number"})
+		return
+	}
+
+	preview, err := h.service.PreviewRecurrence(c.Request.Context(), id, count, queryBool(c, "all_users"))
+	if err != nil {
+		respondRecurrenceError(c, err, "Failed to preview recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// respondRecurrenceError maps recurrence service errors to HTTP responses
+func respondRecurrenceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
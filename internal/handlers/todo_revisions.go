@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// PaginatedRevisionsResponse represents a page of a todo's revision history
+type PaginatedRevisionsResponse struct {
+	Data       []*models.TodoRevision `json:"data"`
+	Pagination PaginationMeta         `json:"pagination"`
+}
+
+// History handles GET /todos/:id/history
+// @Summary List a todo's revisions
+// @Description Get every recorded change to a todo, newest first, with who made it and the before/after value of each changed field
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param all_users query bool false "Admins only: access any user's todo"
+// @Success 200 {object} PaginatedRevisionsResponse "Revisions with pagination"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/history [get]
+func (h *TodoHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	revisions, totalCount, err := h.service.History(c.Request.Context(), id, page, pageSize, queryBool(c, "all_users"))
+	if err != nil {
+		respondRevisionError(c, err, "Failed to get history")
+		return
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, PaginatedRevisionsResponse{
+		Data: revisions,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// Revision handles GET /todos/:id/history/:rev
+// @Summary Get a todo revision
+// @Description Get one revision of a todo with its field changes and a snapshot of the todo right after it
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param rev path int true "Revision number"
+// @Param all_users query bool false "Admins only: access any user's todo"
+// @Success 200 {object} models.TodoRevision "Revision with snapshot"
+// @Failure 400 {object} ErrorResponse "Invalid ID or revision format"
+// @Failure 403 {object} ErrorResponse "all_users requested by a non-admin"
+// @Failure 404 {object} ErrorResponse "Todo or revision not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/history/{rev} [get]
+func (h *TodoHandler) Revision(c *gin.Context) {
+	id, rev, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.Revision(c.Request.Context(), id, rev, queryBool(c, "all_users"))
+	if err != nil {
+		respondRevisionError(c, err, "Failed to get revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// Revert handles POST /todos/:id/revert/:rev
+// @Summary Revert a todo to an earlier revision
+// @Description Restore title, description, completion, dates, priority, tags, project, parent and workflow state to their state right after
+// @Description the given revision. The revert is recorded as a new revision. Trashed todos have to be restored first
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.Todo "Reverted todo"
+// @Failure 400 {object} ErrorResponse "Invalid ID or revision format, the old project or parent is no longer valid, or the workflow does not allow going back to the old state"
+// @Failure 403 {object} ErrorResponse "Editor access to the todo is required, and owner access to restore an earlier project"
+// @Failure 404 {object} ErrorResponse "Todo or revision not found"
+// @Failure 409 {object} ErrorResponse "Reverting would complete a todo whose blockers are still open, or the old state is at its WIP limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/revert/{rev} [post]
+func (h *TodoHandler) Revert(c *gin.Context) {
+	id, rev, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	todo, err := h.service.Revert(c.Request.Context(), id, rev)
+	if err != nil {
+		respondRevisionError(c, err, "Failed to revert todo")
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// parseRevisionParams reads the todo ID and revision number from the path,
+// responding with 400 when either is malformed
+func parseRevisionParams(c *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return 0, 0, false
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || rev < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid revision format"})
+		return 0, 0, false
+	}
+
+	return id, rev, true
+}
+
+// respondRevisionError maps revision history service errors to HTTP responses
+func respondRevisionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found"})
+	case errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Revision not found"})
+	case errors.Is(err, service.ErrTodoBlocked), errors.Is(err, service.ErrWIPLimitReached):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// Trash handles GET /todos/trash
+// @Summary List deleted todos
+// @Description Get the trashed todos the current user owns, as their creator or the owner of their project, most recently deleted first. Trashed todos are purged once the retention period has passed
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} PaginatedTodosResponse "List of trashed todos with pagination"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/trash [get]
+func (h *TodoHandler) Trash(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = service.NormalizePagination(page, pageSize)
+
+	todos, totalCount, err := h.service.ListTrash(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.respondListError(c, err)
+		return
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, PaginatedTodosResponse{
+		Data: todos,
+		Pagination: PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: &totalCount,
+			TotalPages: &totalPages,
+		},
+	})
+}
+
+// Restore handles POST /todos/:id/restore
+// @Summary Restore a deleted todo
+// @Description Bring a todo back from the trash together with the subtasks that were deleted with it.
+// @Description If its parent todo is still in the trash, the todo is restored as a top-level todo
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} models.Todo "Restored todo"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 403 {object} ErrorResponse "Owner access to the todo is required"
+// @Failure 404 {object} ErrorResponse "Todo not found in the trash"
+// @Failure 409 {object} ErrorResponse "The todo's workflow state is at its WIP limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /todos/{id}/restore [post]
+func (h *TodoHandler) Restore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	todo, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTodoNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Todo not found in the trash"})
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrWIPLimitReached):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to restore todo"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
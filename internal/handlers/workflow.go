@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/service"
+)
+
+// WorkflowHandler handles HTTP requests for project workflows and boards
+type WorkflowHandler struct {
+	service *service.WorkflowService
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(service *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{service: service}
+}
+
+// Get handles GET /projects/:id/workflow
+// @Summary Get a project's workflow
+// @Description Get the states of a project's workflow in board order and the transitions allowed between them
+// @Description A project without states has no workflow; an empty transition list allows moving between any two states
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Workflow "Project workflow"
+// @Failure 400 {object} ErrorResponse "Invalid ID format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/workflow [get]
+func (h *WorkflowHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	workflow, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWorkflowError(c, err, "Failed to get workflow")
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// Replace handles PUT /projects/:id/workflow
+// @Summary Replace a project's workflow
+// @Description Set the states of a project's workflow in board order and the transitions allowed between them, referring to states by name
+// @Description Keep a state by sending its id; states left out are removed. Entering a terminal state completes a todo
+// @Description Todos in removed states, or whose completion no longer matches their state's, move to the first state that fits, within its WIP limit
+// @Description Send no states to remove the workflow
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param workflow body models.WorkflowRequest true "Workflow states and transitions"
+// @Success 200 {object} models.Workflow "Updated workflow"
+// @Failure 400 {object} ErrorResponse "Invalid request body or workflow"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Only the owner of a project can change its workflow"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 409 {object} ErrorResponse "Moving the todos would take a state over its WIP limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/workflow [put]
+func (h *WorkflowHandler) Replace(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req models.WorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	workflow, err := h.service.Replace(c.Request.Context(), id, &req)
+	if err != nil {
+		respondWorkflowError(c, err, "Failed to update workflow")
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// Board handles GET /projects/:id/board
+// @Summary Get a project's board
+// @Description Get the todos of a project grouped by workflow state, in board order, each column in manual order
+// @Description count is the number of todos in a state; over_limit flags states holding more todos than their WIP limit
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param page_size query int false "Todos listed per column (default: 20, max: 100)"
+// @Success 200 {object} models.Board "Project board"
+// @Failure 400 {object} ErrorResponse "Invalid ID format, or the project has no workflow"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Project not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /projects/{id}/board [get]
+func (h *WorkflowHandler) Board(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	board, err := h.service.Board(c.Request.Context(), id, pageSize)
+	if err != nil {
+		respondWorkflowError(c, err, "Failed to get board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// respondWorkflowError maps workflow service errors to HTTP responses
+func respondWorkflowError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrWIPLimitReached):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
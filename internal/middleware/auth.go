@@ -46,6 +46,7 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			Email:    claims.Email,
 			Username: claims.Username,
 			IsAdmin:  claims.IsAdmin,
+			Timezone: claims.Timezone,
 		}
 
 		// Set user context in Gin context
@@ -106,6 +107,7 @@ func OptionalAuth(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			Email:    claims.Email,
 			Username: claims.Username,
 			IsAdmin:  claims.IsAdmin,
+			Timezone: claims.Timezone,
 		}
 
 		c.Set("user", userContext)
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment describes a file uploaded to a todo; the content lives in blob storage
+type Attachment struct {
+	ID          int       `json:"id" db:"id"`
+	TodoID      int       `json:"todo_id" db:"todo_id"`
+	Filename    string    `json:"filename" db:"filename" example:"receipt.pdf"`
+	ContentType string    `json:"content_type" db:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size" db:"size" example:"48213"` // in bytes
+	StorageKey  string    `json:"-" db:"storage_key"`
+	UploadedBy  *int      `json:"uploaded_by,omitempty" db:"uploaded_by" example:"1"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
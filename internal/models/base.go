@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -55,6 +56,7 @@ type UserContext struct {
 	Email    string
 	Username string
 	IsAdmin  bool
+	Timezone string
 }
 
 // GetUserIDFromContext extracts user ID from context
@@ -73,6 +75,27 @@ func GetUserFromContext(ctx context.Context) *UserContext {
 	return nil
 }
 
+// LoadTimezone looks up an IANA timezone by name
+// "Local" is refused: it is whatever zone the server runs in, which the database doesn't know by that name
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Location returns the user's timezone, falling back to UTC when it is unset or unknown
+func (u *UserContext) Location() *time.Location {
+	if u == nil || u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := LoadTimezone(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // SetUserInContext sets user information in context
 func SetUserInContext(ctx context.Context, user *UserContext) context.Context {
 	return context.WithValue(ctx, UserContextKey, user)
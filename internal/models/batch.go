@@ -0,0 +1,39 @@
+package models
+
+// BatchOp names the kind of a batch operation
+type BatchOp string
+
+const (
+	BatchCreate   BatchOp = "create"
+	BatchUpdate   BatchOp = "update"
+	BatchDelete   BatchOp = "delete"
+	BatchComplete BatchOp = "complete"
+)
+
+// BatchRequest lists todo operations to run in one transaction
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,dive"`
+	// ContinueOnError commits the operations that succeed instead of rolling everything back
+	ContinueOnError bool `json:"continue_on_error,omitempty" example:"false"`
+}
+
+// BatchOperation is one operation of a batch
+// create takes Create; update takes ID and Update; delete and complete take ID
+type BatchOperation struct {
+	Op      BatchOp            `json:"op" binding:"required,oneof=create update delete complete" enums:"create,update,delete,complete" example:"complete"`
+	ID      int                `json:"id,omitempty" binding:"omitempty,min=1" example:"42"`
+	Create  *CreateTodoRequest `json:"create,omitempty"`
+	Update  *UpdateTodoRequest `json:"update,omitempty"`
+	Version *int               `json:"version,omitempty" example:"3"`   // applies the operation only at this version, like If-Match
+	Force   bool               `json:"force,omitempty" example:"false"` // complete even while blockers are open
+}
+
+// BatchResult is the outcome of one batch operation
+// Todo is nil for deletes and failed operations
+type BatchResult struct {
+	Index int
+	Op    BatchOp
+	ID    int
+	Todo  *Todo
+	Err   error
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a message left on a todo
+type Comment struct {
+	ID       int       `json:"id" db:"id"`
+	TodoID   int       `json:"todo_id" db:"todo_id"`
+	AuthorID *int      `json:"author_id,omitempty" db:"author_id"` // unset once the author's account is deleted
+	Author   *UserInfo `json:"author,omitempty" db:"-"`
+	Body     string    `json:"body" db:"body"`
+	// EditedAt is set once the body has been changed after posting
+	EditedAt  *time.Time `json:"edited_at,omitempty" db:"edited_at" swaggertype:"string" example:"2024-01-15T16:00:00Z"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCommentRequest represents the data needed to comment on a todo
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=5000" example:"Picked up the milk, still need eggs"`
+}
+
+// UpdateCommentRequest replaces the body of a comment
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=5000" example:"Picked up milk and eggs"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// MoveTodoRequest places a todo between its siblings in manual order
+// before names the todo it should come right before, after the one it should follow;
+// with both, the todo lands between them
+type MoveTodoRequest struct {
+	Before *int `json:"before,omitempty" binding:"omitempty,min=1" example:"12"`
+	After  *int `json:"after,omitempty" binding:"omitempty,min=1" example:"11"`
+}
+
+// PositionScope identifies the list a todo is ordered within: the todos of one project,
+// or the inbox of one user, at one subtask level
+type PositionScope struct {
+	ProjectID *int
+	ParentID  *int
+	OwnerID   *int // only set for the inbox, where lists are per user
+}
+
+// Equal reports whether two scopes identify the same list
+func (s PositionScope) Equal(other PositionScope) bool {
+	return sameOptionalID(s.ProjectID, other.ProjectID) &&
+		sameOptionalID(s.ParentID, other.ParentID) &&
+		sameOptionalID(s.OwnerID, other.OwnerID)
+}
+
+// sameOptionalID reports whether two optional IDs are both unset or equal
+func sameOptionalID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PositionScope returns the list the todo is ordered within
+func (t *Todo) PositionScope() PositionScope {
+	scope := PositionScope{ProjectID: t.ProjectID, ParentID: t.ParentID}
+	if t.ProjectID == nil {
+		scope.OwnerID = t.CreatedBy
+	}
+	return scope
+}
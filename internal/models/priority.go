@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Priority expresses how urgent a todo is
+// It is exposed as a name in JSON and stored as its rank so the database can sort by it
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities lists all priorities from least to most urgent; the index is the rank
+var Priorities = []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Rank returns the position of the priority in Priorities, or -1 if it is unknown
+func (p Priority) Rank() int {
+	for rank, priority := range Priorities {
+		if p == priority {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Valid reports whether p is one of the known priorities
+func (p Priority) Valid() bool {
+	return p.Rank() >= 0
+}
+
+// Value stores the priority as its rank
+func (p Priority) Value() (driver.Value, error) {
+	if p == "" {
+		return int64(0), nil
+	}
+	rank := p.Rank()
+	if rank < 0 {
+		return nil, fmt.Errorf("invalid priority %q", p)
+	}
+	return int64(rank), nil
+}
+
+// Scan reads a priority rank from the database
+func (p *Priority) Scan(src interface{}) error {
+	rank, ok := src.(int64)
+	if !ok || rank < 0 || int(rank) >= len(Priorities) {
+		return fmt.Errorf("invalid priority rank %v", src)
+	}
+	*p = Priorities[rank]
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Project groups todos into a list; projects can be nested inside a parent to form folders
+// Todos without a project live in the user's inbox
+type Project struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	ParentID  *int      `json:"parent_id,omitempty" db:"parent_id"`
+	Name      string    `json:"name" db:"name"`
+	Color     string    `json:"color" db:"color"`
+	Archived  bool      `json:"archived" db:"archived"`
+	TodoCount int       `json:"todo_count" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateProjectRequest represents the data needed to create a project
+type CreateProjectRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100" example:"Home renovation"`
+	Color    string `json:"color" binding:"omitempty,hexcolor" example:"#3366ff"`
+	ParentID *int   `json:"parent_id,omitempty" example:"1"`
+}
+
+// UpdateProjectRequest represents the project fields that can be updated
+type UpdateProjectRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,min=1,max=100" example:"Kitchen renovation"`
+	Color    *string `json:"color,omitempty" binding:"omitempty,hexcolor" example:"#33aa66"`
+	Archived *bool   `json:"archived,omitempty" example:"true"`
+	ParentID *int    `json:"parent_id,omitempty" example:"2"` // 0 moves the project to the top level
+}
+
+// ProjectDeleteMode decides what happens to a project's contents when it is deleted
+type ProjectDeleteMode string
+
+const (
+	// ProjectDeleteMove moves the project's todos to the inbox and its subprojects up one level
+	ProjectDeleteMove ProjectDeleteMode = "move"
+	// ProjectDeleteCascade deletes the project's subprojects and every todo in them
+	ProjectDeleteCascade ProjectDeleteMode = "cascade"
+)
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// RevisionAction names the kind of write a revision records
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+	RevisionRevert  RevisionAction = "revert"
+)
+
+// TodoRevision records one change to a todo: who made it, when, and which fields changed
+type TodoRevision struct {
+	Rev       int                    `json:"rev" example:"3"` // counts up from 1 per todo
+	TodoID    int                    `json:"todo_id" example:"42"`
+	Action    RevisionAction         `json:"action" enums:"create,update,delete,restore,revert" example:"update"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedBy *int                   `json:"created_by,omitempty" example:"1"`
+	CreatedAt time.Time              `json:"created_at" swaggertype:"string" example:"2024-01-15T15:04:05Z"`
+
+	// Snapshot is the todo as it was right after this revision; only included for a single revision
+	Snapshot *TodoSnapshot `json:"snapshot,omitempty"`
+}
+
+// FieldChange holds the JSON values of a field before and after a revision
+// Before is null for fields set when the todo was created
+type FieldChange struct {
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	After  json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// TodoSnapshot holds the user-editable state of a todo that revisions track
+type TodoSnapshot struct {
+	Title       string     `json:"title" example:"Buy groceries"`
+	Description string     `json:"description" example:"Milk, bread, eggs, and cheese"`
+	Completed   bool       `json:"completed" example:"false"`
+	CompletedAt *time.Time `json:"completed_at" swaggertype:"string" example:"2024-01-15T15:04:05Z"`
+	DueAt       *time.Time `json:"due_at" swaggertype:"string" example:"2024-01-20T17:00:00+01:00"`
+	StartAt     *time.Time `json:"start_at" swaggertype:"string" example:"2024-01-18T09:00:00+01:00"`
+	Priority    Priority   `json:"priority" enums:"none,low,medium,high,urgent" example:"high"`
+	Tags        []string   `json:"tags" example:"errands,weekend"`
+	ProjectID   *int       `json:"project_id" example:"1"`
+	ParentID    *int       `json:"parent_id" example:"7"`
+	AssigneeID  *int       `json:"assignee_id" example:"2"`
+	StatusID    *int       `json:"status_id" example:"3"`
+	DeletedAt   *time.Time `json:"deleted_at" swaggertype:"string" example:"2024-01-22T10:00:00Z"`
+}
+
+// Snapshot captures the fields of a todo that revisions track
+func (t *Todo) Snapshot() *TodoSnapshot {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &TodoSnapshot{
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		CompletedAt: t.CompletedAt,
+		DueAt:       t.DueAt,
+		StartAt:     t.StartAt,
+		Priority:    t.Priority,
+		Tags:        tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		AssigneeID:  t.AssigneeID,
+		StatusID:    t.StatusID,
+		DeletedAt:   t.DeletedAt,
+	}
+}
+
+// DiffSnapshots lists the fields whose JSON value differs between two snapshots
+// A nil before reports every field, as when a todo is created
+func DiffSnapshots(before, after *TodoSnapshot) (map[string]FieldChange, error) {
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeFields := map[string]json.RawMessage{}
+	if before != nil {
+		if beforeFields, err = snapshotFields(before); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]FieldChange{}
+	for field, value := range afterFields {
+		previous, ok := beforeFields[field]
+		if !ok {
+			previous = json.RawMessage("null")
+		} else if bytes.Equal(previous, value) {
+			continue
+		}
+		changes[field] = FieldChange{Before: previous, After: value}
+	}
+
+	return changes, nil
+}
+
+// snapshotFields encodes a snapshot as a map of field name to JSON value
+func snapshotFields(snapshot *TodoSnapshot) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
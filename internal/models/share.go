@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareRole is the access a user has to a todo or project
+type ShareRole string
+
+const (
+	RoleViewer    ShareRole = "viewer"    // can see the todo, its comments and attachments
+	RoleCommenter ShareRole = "commenter" // can also comment
+	RoleEditor    ShareRole = "editor"    // can also change the todo and its attachments
+	RoleOwner     ShareRole = "owner"     // can also delete and share; never granted through a share
+)
+
+// ShareRoles lists all roles from least to most access; the index is the rank
+var ShareRoles = []ShareRole{RoleViewer, RoleCommenter, RoleEditor, RoleOwner}
+
+// Rank returns the position of the role in ShareRoles, or -1 if it is unknown
+func (r ShareRole) Rank() int {
+	for rank, role := range ShareRoles {
+		if r == role {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Grantable reports whether r can be given to another user through a share
+func (r ShareRole) Grantable() bool {
+	return r.Rank() >= 0 && r != RoleOwner
+}
+
+// Includes reports whether r grants at least the access of other
+func (r ShareRole) Includes(other ShareRole) bool {
+	return r.Rank() >= 0 && r.Rank() >= other.Rank()
+}
+
+// ShareKind names what a share gives access to
+type ShareKind string
+
+const (
+	ShareKindTodo    ShareKind = "todo"
+	ShareKindProject ShareKind = "project"
+)
+
+// Share gives a user access to a todo or to every todo filed directly in a project
+// Exactly one of TodoID and ProjectID is set
+type Share struct {
+	ID        int       `json:"id" db:"id"`
+	TodoID    *int      `json:"todo_id,omitempty" db:"todo_id" example:"42"`
+	ProjectID *int      `json:"project_id,omitempty" db:"project_id"`
+	UserID    int       `json:"user_id" db:"user_id" example:"2"`
+	User      *UserInfo `json:"user,omitempty" db:"-"`
+	Role      ShareRole `json:"role" db:"role" enums:"viewer,commenter,editor" example:"editor"`
+	InvitedBy *int      `json:"invited_by,omitempty" db:"invited_by" example:"1"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ShareRequest invites a user, identified by ID or email, or changes the role they already have
+type ShareRequest struct {
+	UserID *int      `json:"user_id,omitempty" binding:"omitempty,min=1" example:"2"`
+	Email  string    `json:"email,omitempty" binding:"omitempty,email" example:"jane@example.com"`
+	Role   ShareRole `json:"role" binding:"required" enums:"viewer,commenter,editor" example:"editor"`
+}
+
+// SharedTodo is a todo shared with the current user, with the role they were given
+type SharedTodo struct {
+	Todo
+	Role ShareRole `json:"role" enums:"viewer,commenter,editor" example:"commenter"`
+}
+
+// SharedProject is a project shared with the current user, with the role they were given
+type SharedProject struct {
+	Project
+	Role ShareRole `json:"role" enums:"viewer,commenter,editor" example:"viewer"`
+}
+
+// SharedWithMe lists everything other users have shared with the current user
+type SharedWithMe struct {
+	Todos    []*SharedTodo    `json:"todos"`
+	Projects []*SharedProject `json:"projects"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareLink gives anyone who has its token read-only access to a todo or project
+// Exactly one of TodoID and ProjectID is set
+type ShareLink struct {
+	ID        int  `json:"id" db:"id"`
+	TodoID    *int `json:"todo_id,omitempty" db:"todo_id" example:"42"`
+	ProjectID *int `json:"project_id,omitempty" db:"project_id"`
+	// Token is only returned when the link is created; only its hash is stored
+	Token        string     `json:"token,omitempty" db:"-" example:"q3XH0dM2m0bB6cQeR8wq1Yk3hZ2pJf4uVtN5sLxA9oE"`
+	HasPassword  bool       `json:"has_password" db:"-" example:"true"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at" swaggertype:"string" example:"2024-02-01T00:00:00Z"`
+	ViewCount    int64      `json:"view_count" db:"view_count" example:"12"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty" db:"last_viewed_at" swaggertype:"string" example:"2024-01-20T08:30:00Z"`
+	CreatedBy    int        `json:"created_by" db:"created_by" example:"1"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Expired reports whether the link can no longer be used at now
+func (l *ShareLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// ShareLinkRequest represents the options of a new share link
+type ShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty" swaggertype:"string" example:"2024-02-01T00:00:00Z"` // omit for a link that never expires
+	Password  string     `json:"password,omitempty" binding:"omitempty,min=4,max=72" example:"hunter22"`   // visitors must send it in X-Share-Password
+}
+
+// PublicTodo is the read-only projection of a todo served through share links
+// It leaves out who created, changed or is assigned the todo
+type PublicTodo struct {
+	ID              int              `json:"id" example:"42"`
+	Title           string           `json:"title" example:"Buy groceries"`
+	Description     string           `json:"description" example:"Milk, bread, eggs, and cheese"`
+	Completed       bool             `json:"completed" example:"false"`
+	CompletedAt     *time.Time       `json:"completed_at,omitempty" swaggertype:"string" example:"2024-01-15T15:04:05Z"`
+	DueAt           *time.Time       `json:"due_at,omitempty" swaggertype:"string" example:"2024-01-20T17:00:00+01:00"`
+	StartAt         *time.Time       `json:"start_at,omitempty" swaggertype:"string" example:"2024-01-18T09:00:00+01:00"`
+	Priority        Priority         `json:"priority" enums:"none,low,medium,high,urgent" example:"high"`
+	Tags            []string         `json:"tags" example:"errands,weekend"`
+	ParentID        *int             `json:"parent_id,omitempty" example:"7"`
+	SubtaskProgress *SubtaskProgress `json:"subtask_progress,omitempty"`
+	Subtasks        []*PublicTodo    `json:"subtasks,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// Public projects a todo and its expanded subtasks for share links
+func (t *Todo) Public() *PublicTodo {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	public := &PublicTodo{
+		ID:              t.ID,
+		Title:           t.Title,
+		Description:     t.Description,
+		Completed:       t.Completed,
+		CompletedAt:     t.CompletedAt,
+		DueAt:           t.DueAt,
+		StartAt:         t.StartAt,
+		Priority:        t.Priority,
+		Tags:            tags,
+		ParentID:        t.ParentID,
+		SubtaskProgress: t.SubtaskProgress,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+	for _, subtask := range t.Subtasks {
+		public.Subtasks = append(public.Subtasks, subtask.Public())
+	}
+
+	return public
+}
+
+// PublicProject is the read-only projection of a project served through share links
+type PublicProject struct {
+	Name  string `json:"name" example:"Home renovation"`
+	Color string `json:"color" example:"#3366ff"`
+}
+
+// PublicShare is what a share link shows: a todo, or a project with a page of its todos
+type PublicShare struct {
+	Kind       ShareKind      `json:"kind" enums:"todo,project" example:"todo"`
+	Todo       *PublicTodo    `json:"todo,omitempty"`
+	Project    *PublicProject `json:"project,omitempty"`
+	Todos      []*PublicTodo  `json:"todos,omitempty"`
+	TotalCount int            `json:"total_count,omitempty" example:"25"`
+	Page       int            `json:"page,omitempty" example:"1"`
+	PageSize   int            `json:"page_size,omitempty" example:"20"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a user-owned label that can be attached to any number of todos
+type Tag struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Color     string    `json:"color" db:"color"`
+	TodoCount int       `json:"todo_count" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateTagRequest represents the data needed to create a tag
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=50" example:"errands"`
+	Color string `json:"color" binding:"omitempty,hexcolor" example:"#ff8800"`
+}
+
+// UpdateTagRequest represents the tag fields that can be updated
+type UpdateTagRequest struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=50" example:"shopping"`
+	Color *string `json:"color,omitempty" binding:"omitempty,hexcolor" example:"#00aa55"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// TimeEntry records a span of time a user spent on a todo
+type TimeEntry struct {
+	ID        int        `json:"id" db:"id"`
+	TodoID    int        `json:"todo_id" db:"todo_id" example:"42"`
+	UserID    int        `json:"user_id" db:"user_id" example:"1"`
+	StartedAt time.Time  `json:"started_at" db:"started_at" swaggertype:"string" example:"2024-01-15T09:00:00Z"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" db:"ended_at" swaggertype:"string" example:"2024-01-15T10:30:00Z"` // unset while the timer runs
+	// Seconds is the length of the entry; a running timer counts up to now
+	Seconds   int64     `json:"seconds" db:"-" example:"5400"`
+	Note      string    `json:"note" db:"note" example:"Client call about the Q1 report"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Running reports whether the entry is a timer that hasn't been stopped
+func (e *TimeEntry) Running() bool {
+	return e.EndedAt == nil
+}
+
+// CreateTimeEntryRequest represents a time entry logged by hand
+type CreateTimeEntryRequest struct {
+	StartedAt time.Time `json:"started_at" binding:"required" swaggertype:"string" example:"2024-01-15T09:00:00Z"`
+	EndedAt   time.Time `json:"ended_at" binding:"required" swaggertype:"string" example:"2024-01-15T10:30:00Z"`
+	Note      string    `json:"note" binding:"max=500" example:"Client call about the Q1 report"`
+}
+
+// UpdateTimeEntryRequest replaces the times and note of a time entry
+// ended_at may only be left out while the entry's timer is running
+type UpdateTimeEntryRequest struct {
+	StartedAt time.Time  `json:"started_at" binding:"required" swaggertype:"string" example:"2024-01-15T09:00:00Z"`
+	EndedAt   *time.Time `json:"ended_at" swaggertype:"string" example:"2024-01-15T10:30:00Z"`
+	Note      string     `json:"note" binding:"max=500" example:"Client call about the Q1 report"`
+}
+
+// TimeEntryFilter narrows down time entries; bounds apply to started_at
+type TimeEntryFilter struct {
+	// UserID restricts results to one user's entries; set by the service, never by clients
+	UserID *int
+
+	TodoID    *int
+	ProjectID *int
+	Inbox     bool // entries on todos that aren't in any project
+	From      *time.Time
+	To        *time.Time
+	Running   *bool
+}
+
+// TimeGrouping names what time totals are summed by
+type TimeGrouping string
+
+const (
+	GroupByTodo    TimeGrouping = "todo"
+	GroupByProject TimeGrouping = "project"
+	GroupByDay     TimeGrouping = "day"
+)
+
+// IsValid reports whether g is a known grouping
+func (g TimeGrouping) IsValid() bool {
+	switch g {
+	case GroupByTodo, GroupByProject, GroupByDay:
+		return true
+	}
+	return false
+}
+
+// TimeTotal is the time logged on one todo, project or day
+// Only the key matching the grouping is set; a project total without project_id covers the inbox
+type TimeTotal struct {
+	TodoID    *int    `json:"todo_id,omitempty" example:"42"`
+	ProjectID *int    `json:"project_id,omitempty" example:"1"`
+	Day       *string `json:"day,omitempty" example:"2024-01-15"`
+	Name      string  `json:"name,omitempty" example:"Quarterly report"` // the todo's title or the project's name
+	Seconds   int64   `json:"seconds" example:"5400"`
+}
+
+// TimeTotals sums time entries by todo, project or day
+type TimeTotals struct {
+	GroupBy  TimeGrouping `json:"group_by" enums:"todo,project,day" example:"day"`
+	Timezone string       `json:"timezone" example:"Europe/Berlin"` // the timezone days are counted in
+	Seconds  int64        `json:"seconds" example:"27000"`
+	Totals   []*TimeTotal `json:"totals"`
+}
@@ -1,53 +1,346 @@
-package models
-
-import (
-	"time"
-)
-
-// Todo represents a task in our system
-// Now includes audit fields through BaseModel
-type Todo struct {
-	ID          int        `json:"id" db:"id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	Completed   bool       `json:"completed" db:"completed"`
-	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at" swaggertype:"string" example:"2024-01-15T15:04:05Z"`
-	BaseModel              // Embedded audit fields
-}
-
-// CreateTodoRequest represents the data needed to create a new todo
-// We separate this from the Todo model to control what users can set
-type CreateTodoRequest struct {
-	Title       string `json:"title" binding:"required,min=1,max=200" example:"Buy groceries"`
-	Description string `json:"description" binding:"max=1000" example:"Milk, bread, eggs, and cheese"`
-}
-
-// UpdateTodoRequest represents the data that can be updated
-// Using pointers allows us to distinguish between "not provided" and "empty"
-type UpdateTodoRequest struct {
-	Title       *string `json:"title,omitempty" binding:"omitempty,min=1,max=200" example:"Buy groceries and supplies"`
-	Description *string `json:"description,omitempty" binding:"omitempty,max=1000" example:"Milk, bread, eggs, cheese, and cleaning supplies"`
-	Completed   *bool   `json:"completed,omitempty" example:"true"`
-}
-
-// TodoWithUser includes user information for created_by and updated_by
-type TodoWithUser struct {
-	ID            int        `json:"id"`
-	Title         string     `json:"title"`
-	Description   string     `json:"description"`
-	Completed     bool       `json:"completed"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	CreatedBy     *int       `json:"created_by,omitempty"`
-	UpdatedBy     *int       `json:"updated_by,omitempty"`
-	CreatedByUser *UserInfo  `json:"created_by_user,omitempty"`
-	UpdatedByUser *UserInfo  `json:"updated_by_user,omitempty"`
-}
-
-// UserInfo represents basic user information for display
-type UserInfo struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-}
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Todo represents a task in our system
+// Now includes audit fields through BaseModel
+type Todo struct {
+	ID          int         `json:"id" db:"id"`
+	Title       string      `json:"title" db:"title"`
+	Description string      `json:"description" db:"description"`
+	Completed   bool        `json:"completed" db:"completed"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty" db:"completed_at" swaggertype:"string" example:"2024-01-15T15:04:05Z"`
+	DueAt       *time.Time  `json:"due_at,omitempty" db:"due_at" swaggertype:"string" example:"2024-01-20T17:00:00+01:00"`
+	StartAt     *time.Time  `json:"start_at,omitempty" db:"start_at" swaggertype:"string" example:"2024-01-18T09:00:00+01:00"`
+	Priority    Priority    `json:"priority" db:"priority" enums:"none,low,medium,high,urgent" example:"high"`
+	Tags        []string    `json:"tags" db:"-" example:"errands,weekend"`
+	ProjectID   *int        `json:"project_id,omitempty" db:"project_id" example:"1"`
+	ParentID    *int        `json:"parent_id,omitempty" db:"parent_id" example:"7"`
+	AssigneeID  *int        `json:"assignee_id,omitempty" db:"assignee_id" example:"2"`
+	StatusID    *int        `json:"status_id,omitempty" db:"status_id" example:"3"` // state in the project's workflow, if it has one
+	Recurrence  *Recurrence `json:"recurrence,omitempty" db:"-"`
+	SeriesID    *int        `json:"series_id,omitempty" db:"series_id" example:"4"` // first todo of its recurring series
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty" db:"deleted_at" swaggertype:"string" example:"2024-01-22T10:00:00Z"`
+	DeletedBy   *int        `json:"deleted_by,omitempty" db:"deleted_by" example:"1"`
+	Version     int         `json:"version" db:"version" example:"3"`   // incremented on every change; served as the ETag
+	Position    string      `json:"position" db:"position" example:"V"` // orders the todo within its list with sort=position
+	BaseModel               // Embedded audit fields
+
+	// AssignedToUser describes the assignee; it is omitted for unassigned todos
+	AssignedToUser *UserInfo `json:"assigned_to_user,omitempty" db:"-"`
+	// IsBlocked is set while any todo this one is blocked by is still open
+	IsBlocked bool `json:"is_blocked" db:"-"`
+	// CommentCount is the number of comments on the todo
+	CommentCount int `json:"comment_count" db:"-"`
+	// SubtaskProgress counts the direct subtasks; it is omitted for todos without any
+	SubtaskProgress *SubtaskProgress `json:"subtask_progress,omitempty" db:"-"`
+	// Subtasks is only populated when the subtask tree is requested with include=subtasks
+	Subtasks []*Todo `json:"subtasks,omitempty" db:"-"`
+	// NextOccurrence is only populated in the response that completes a recurring todo
+	NextOccurrence *Todo `json:"next_occurrence,omitempty" db:"-"`
+}
+
+// Recurrence makes a todo repeat: completing it creates the next occurrence
+// with the due date the rule yields after the current one
+type Recurrence struct {
+	Rule     string    `json:"rule" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+	Timezone string    `json:"timezone" example:"Europe/Berlin"`
+	Start    time.Time `json:"start" swaggertype:"string" example:"2024-01-15T09:00:00+01:00"` // DTSTART the rule counts from
+}
+
+// RecurrenceRequest sets a todo's recurrence rule
+type RecurrenceRequest struct {
+	// Rule is an RFC 5545 RRULE without DTSTART, e.g. FREQ=MONTHLY;BYMONTHDAY=1
+	Rule string `json:"rule" binding:"required,max=500" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+	// Timezone the rule is evaluated in; defaults to the user's timezone
+	Timezone string `json:"timezone,omitempty" binding:"max=64" example:"Europe/Berlin"`
+}
+
+// RecurrencePreview lists upcoming occurrences of a recurring todo
+type RecurrencePreview struct {
+	Rule        string      `json:"rule" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+	Timezone    string      `json:"timezone" example:"Europe/Berlin"`
+	Occurrences []time.Time `json:"occurrences" swaggertype:"array,string"`
+}
+
+// SubtaskProgress reports how many of a todo's direct subtasks are completed
+type SubtaskProgress struct {
+	Done  int `json:"done" example:"2"`
+	Total int `json:"total" example:"5"`
+}
+
+// CreateTodoRequest represents the data needed to create a new todo
+// We separate this from the Todo model to control what users can set
+type CreateTodoRequest struct {
+	Title       string             `json:"title" binding:"required,min=1,max=200" example:"Buy groceries"`
+	Description string             `json:"description" binding:"max=1000" example:"Milk, bread, eggs, and cheese"`
+	DueAt       *time.Time         `json:"due_at,omitempty" swaggertype:"string" example:"2024-01-20T17:00:00+01:00"`
+	StartAt     *time.Time         `json:"start_at,omitempty" swaggertype:"string" example:"2024-01-18T09:00:00+01:00"`
+	Priority    Priority           `json:"priority,omitempty" enums:"none,low,medium,high,urgent" example:"medium"` // defaults to none
+	Tags        []string           `json:"tags,omitempty" example:"errands,weekend"`                                // created on the fly if missing
+	ProjectID   *int               `json:"project_id,omitempty" example:"1"`                                        // omit for the inbox
+	ParentID    *int               `json:"parent_id,omitempty" example:"7"`                                         // makes the todo a subtask
+	AssigneeID  *int               `json:"assignee_id,omitempty" example:"2"`                                       // user ID to assign the todo to
+	StatusID    *int               `json:"status_id,omitempty" example:"3"`                                         // defaults to the first open state of the project's workflow
+	Recurrence  *RecurrenceRequest `json:"recurrence,omitempty"`                                                    // requires due_at
+
+	// CreatedBy files the todo, and its tags, under another user than the one making the request,
+	// such as the next occurrence of a series completed by someone else than its owner
+	CreatedBy *int `json:"-"`
+}
+
+// ReplaceTodoRequest is the full representation of a todo written by PUT
+// Unlike UpdateTodoRequest, omitted or null fields are cleared rather than left alone
+type ReplaceTodoRequest struct {
+	Title       string     `json:"title" binding:"required,min=1,max=200" example:"Buy groceries"`
+	Description string     `json:"description" binding:"max=1000" example:"Milk, bread, eggs, and cheese"`
+	Completed   bool       `json:"completed" example:"false"`
+	DueAt       *time.Time `json:"due_at" swaggertype:"string" example:"2024-01-20T17:00:00+01:00"`
+	StartAt     *time.Time `json:"start_at" swaggertype:"string" example:"2024-01-18T09:00:00+01:00"`
+	Priority    Priority   `json:"priority" enums:"none,low,medium,high,urgent" example:"medium"` // defaults to none
+	Tags        []string   `json:"tags" example:"errands,weekend"`
+	ProjectID   *int       `json:"project_id" example:"1"`  // null for the inbox
+	ParentID    *int       `json:"parent_id" example:"7"`   // null for a top-level todo
+	AssigneeID  *int       `json:"assignee_id" example:"2"` // null for an unassigned todo
+	StatusID    *int       `json:"status_id" example:"3"`   // null follows completed in the project's workflow
+
+	// Force completes the todo even while todos it is blocked by are still open
+	Force bool `json:"force,omitempty" example:"false"`
+	// CompleteSubtasks also completes every open subtask, at any depth, when the todo becomes completed
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty" example:"false"`
+
+	// IfMatch works as in UpdateTodoRequest
+	IfMatch []int `json:"-"`
+}
+
+// UpdateRequest expresses the replacement as an update that sets every field
+func (r *ReplaceTodoRequest) UpdateRequest() *UpdateTodoRequest {
+	tags := r.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	projectID, parentID, assigneeID := 0, 0, 0
+	if r.ProjectID != nil {
+		projectID = *r.ProjectID
+	}
+	if r.ParentID != nil {
+		parentID = *r.ParentID
+	}
+	if r.AssigneeID != nil {
+		assigneeID = *r.AssigneeID
+	}
+
+	return &UpdateTodoRequest{
+		Title:            &r.Title,
+		Description:      &r.Description,
+		Completed:        &r.Completed,
+		DueAt:            r.DueAt,
+		StartAt:          r.StartAt,
+		Priority:         &r.Priority,
+		Tags:             &tags,
+		ProjectID:        &projectID,
+		ParentID:         &parentID,
+		AssigneeID:       &assigneeID,
+		StatusID:         r.StatusID,
+		Force:            r.Force,
+		CompleteSubtasks: r.CompleteSubtasks,
+		IfMatch:          r.IfMatch,
+		ClearDueAt:       r.DueAt == nil,
+		ClearStartAt:     r.StartAt == nil,
+	}
+}
+
+// Replacement returns the todo's current state as a full replacement, the document PATCH is applied to
+func (t *Todo) Replacement() *ReplaceTodoRequest {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &ReplaceTodoRequest{
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		DueAt:       t.DueAt,
+		StartAt:     t.StartAt,
+		Priority:    t.Priority,
+		Tags:        tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		AssigneeID:  t.AssigneeID,
+		StatusID:    t.StatusID,
+	}
+}
+
+// PatchFormat names the patch document format of a PATCH request
+type PatchFormat string
+
+const (
+	MergePatch PatchFormat = "application/merge-patch+json" // RFC 7396
+	JSONPatch  PatchFormat = "application/json-patch+json"  // RFC 6902
+)
+
+// PatchTodoRequest carries a PATCH body, applied to the todo's Replacement document,
+// together with the options sent alongside it
+type PatchTodoRequest struct {
+	Format           PatchFormat
+	Patch            []byte
+	Force            bool
+	CompleteSubtasks bool
+	IfMatch          []int
+}
+
+// UpdateTodoRequest represents the data that can be updated
+// Using pointers allows us to distinguish between "not provided" and "empty"
+type UpdateTodoRequest struct {
+	Title       *string    `json:"title,omitempty" binding:"omitempty,min=1,max=200" example:"Buy groceries and supplies"`
+	Description *string    `json:"description,omitempty" binding:"omitempty,max=1000" example:"Milk, bread, eggs, cheese, and cleaning supplies"`
+	Completed   *bool      `json:"completed,omitempty" example:"true"`
+	DueAt       *time.Time `json:"due_at,omitempty" swaggertype:"string" example:"2024-01-21T17:00:00+01:00"`
+	StartAt     *time.Time `json:"start_at,omitempty" swaggertype:"string" example:"2024-01-19T09:00:00+01:00"`
+	Priority    *Priority  `json:"priority,omitempty" enums:"none,low,medium,high,urgent" example:"urgent"`
+	Tags        *[]string  `json:"tags,omitempty" example:"errands"`  // replaces all tags; [] removes them
+	ProjectID   *int       `json:"project_id,omitempty" example:"2"`  // 0 moves the todo to the inbox
+	ParentID    *int       `json:"parent_id,omitempty" example:"8"`   // 0 turns a subtask into a top-level todo
+	AssigneeID  *int       `json:"assignee_id,omitempty" example:"3"` // 0 unassigns the todo
+	StatusID    *int       `json:"status_id,omitempty" example:"4"`   // moves the todo in its project's workflow, completing it in a terminal state
+
+	// Force completes the todo even while todos it is blocked by are still open
+	Force bool `json:"force,omitempty" example:"false"`
+	// CompleteSubtasks also completes every open subtask, at any depth, when completed is set to true
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty" example:"false"`
+
+	// IfMatch lists the versions the todo must still be at for the update to apply, from the
+	// If-Match header; nil updates unconditionally and an empty list never matches
+	IfMatch []int `json:"-"`
+	// ClearDueAt and ClearStartAt remove the dates; they are set by full replacements only
+	ClearDueAt   bool `json:"-"`
+	ClearStartAt bool `json:"-"`
+}
+
+// TodoFilter narrows down todo listings
+// nil fields and an empty Query are not applied
+type TodoFilter struct {
+	// OwnerID restricts results to the todos the caller created or is assigned, or in the trash to the
+	// todos they own; set by the service, never by clients
+	OwnerID *int
+
+	CreatedBy       *int
+	Completed       *bool
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	UpdatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+	CompletedAfter  *time.Time
+	CompletedBefore *time.Time
+	DueAfter        *time.Time
+	DueBefore       *time.Time
+	Priorities      []Priority
+	Tags            []string // todos carrying all of these tags
+	TagsAny         []string // todos carrying at least one of these tags
+	ProjectID       *int
+	Inbox           bool // todos that aren't in any project
+	ParentID        *int
+	TopLevel        bool  // todos that aren't subtasks
+	IsBlocked       *bool // todos with (true) or without (false) open blockers
+	AssigneeID      *int
+	AssignedToMe    bool // resolved by the service into AssigneeID
+	Unassigned      bool // todos without an assignee
+	StatusID        *int // todos in this workflow state
+	Deleted         bool // list the trash instead of live todos
+
+	// Relative due date filters, resolved by the service into DueAfter/DueBefore
+	// in the user's timezone (or Timezone when given)
+	Overdue   bool
+	DueToday  bool
+	DueWithin time.Duration
+	Timezone  string
+
+	// Query is a case-insensitive substring matched against title and description
+	Query string
+}
+
+// SortField is one key of a multi-column sort
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TodoSortFields whitelists the fields todo listings can be sorted by
+var TodoSortFields = map[string]bool{
+	"id":           true,
+	"title":        true,
+	"created_at":   true,
+	"updated_at":   true,
+	"completed_at": true,
+	"due_at":       true,
+	"start_at":     true,
+	"priority":     true,
+	"deleted_at":   true,
+	"position":     true,
+}
+
+// DefaultTodoSort is used when the client doesn't ask for a specific order
+var DefaultTodoSort = []SortField{{Field: "created_at", Desc: true}}
+
+// ParseSort parses a comma-separated sort expression such as "-updated_at,title"
+// A leading "-" sorts that field descending; field names are not validated here
+func ParseSort(raw string) []SortField {
+	var fields []SortField
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: part}
+		if strings.HasPrefix(part, "-") {
+			field = SortField{Field: strings.TrimPrefix(part, "-"), Desc: true}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// AddDependencyRequest declares that a todo is blocked by another todo
+type AddDependencyRequest struct {
+	BlockedByID int `json:"blocked_by_id" binding:"required,min=1" example:"3"`
+}
+
+// TodoDependencies lists the todos a todo is blocked by and the todos it blocks
+type TodoDependencies struct {
+	BlockedBy []*Todo `json:"blocked_by"`
+	Blocking  []*Todo `json:"blocking"`
+}
+
+// TodoSearchResult is a todo matched by full-text search
+// Highlights are HTML-escaped and wrap matched terms in <mark></mark>
+type TodoSearchResult struct {
+	Todo
+	Rank                 float64 `json:"rank" example:"0.6"`
+	TitleHighlight       string  `json:"title_highlight" example:"Buy <mark>groceries</mark>"`
+	DescriptionHighlight string  `json:"description_highlight" example:"Milk, bread, eggs"`
+}
+
+// TodoCursor is a keyset position in a todo listing ordered by (created_at, id) descending
+type TodoCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        int       `json:"i"`
+}
+
+// TodoWithUser includes user information for created_by and updated_by
+type TodoWithUser struct {
+	Todo
+	CreatedByUser *UserInfo `json:"created_by_user,omitempty"`
+	UpdatedByUser *UserInfo `json:"updated_by_user,omitempty"`
+}
+
+// UserInfo represents basic user information for display
+type UserInfo struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
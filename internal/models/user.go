@@ -14,6 +14,7 @@ type User struct {
 	LastName     string     `json:"last_name" db:"last_name"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	IsAdmin      bool       `json:"is_admin" db:"is_admin"`
+	Timezone     string     `json:"timezone" db:"timezone"` // IANA name, used for date-relative todo filters
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
 	BaseModel
 }
@@ -25,6 +26,12 @@ type CreateUserRequest struct {
 	Password  string `json:"password" binding:"required,min=8,max=100"`
 	FirstName string `json:"first_name" binding:"max=100"`
 	LastName  string `json:"last_name" binding:"max=100"`
+	Timezone  string `json:"timezone" binding:"max=64" example:"Europe/Berlin"` // IANA name, defaults to UTC
+}
+
+// UpdateTimezoneRequest represents the request to change the user's timezone
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required,max=64" example:"America/New_York"`
 }
 
 // LoginRequest represents the login request
@@ -55,6 +62,7 @@ type UserResponse struct {
 	LastName    string     `json:"last_name"`
 	IsActive    bool       `json:"is_active"`
 	IsAdmin     bool       `json:"is_admin"`
+	Timezone    string     `json:"timezone"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
@@ -70,6 +78,7 @@ func (u *User) ToResponse() *UserResponse {
 		LastName:    u.LastName,
 		IsActive:    u.IsActive,
 		IsAdmin:     u.IsAdmin,
+		Timezone:    u.Timezone,
 		LastLoginAt: u.LastLoginAt,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
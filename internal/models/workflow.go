@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// WorkflowState is one status the todos of a project move through, shown as a column of its board
+type WorkflowState struct {
+	ID        int       `json:"id" db:"id" example:"3"`
+	ProjectID int       `json:"project_id" db:"project_id" example:"1"`
+	Name      string    `json:"name" db:"name" example:"In progress"`
+	Terminal  bool      `json:"terminal" db:"terminal" example:"false"`         // todos in a terminal state are completed
+	WIPLimit  *int      `json:"wip_limit,omitempty" db:"wip_limit" example:"3"` // most todos the state takes at once
+	Position  int       `json:"position" db:"position" example:"1"`             // column order on the board, from 0
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WorkflowTransition allows todos to move from one state to another
+type WorkflowTransition struct {
+	From int `json:"from" example:"2"`
+	To   int `json:"to" example:"3"`
+}
+
+// Workflow holds the states of a project in board order and the moves allowed between them
+// A project without states has no workflow; its todos are simply open or completed
+type Workflow struct {
+	ProjectID   int                  `json:"project_id" example:"1"`
+	States      []*WorkflowState     `json:"states"`
+	Transitions []WorkflowTransition `json:"transitions"` // empty allows every move
+}
+
+// State returns the state with the given ID, or nil if it isn't part of the workflow
+func (w *Workflow) State(id int) *WorkflowState {
+	for _, state := range w.States {
+		if state.ID == id {
+			return state
+		}
+	}
+	return nil
+}
+
+// InitialState returns the first state, in board order, that is terminal or not
+// It is where todos land when they enter the workflow or are completed or reopened without naming a state
+func (w *Workflow) InitialState(terminal bool) *WorkflowState {
+	for _, state := range w.States {
+		if state.Terminal == terminal {
+			return state
+		}
+	}
+	return nil
+}
+
+// Allows reports whether todos may move from one state to another
+func (w *Workflow) Allows(from, to int) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkflowRequest replaces the workflow of a project
+// States are listed in board order; an empty list removes the workflow
+type WorkflowRequest struct {
+	States      []WorkflowStateRequest      `json:"states" binding:"max=20,dive"`
+	Transitions []WorkflowTransitionRequest `json:"transitions" binding:"dive"` // empty allows every move
+}
+
+// WorkflowStateRequest describes one state of a workflow
+// Give the ID of an existing state to keep its todos in it; states left out are removed
+// and their todos move to the first remaining state that matches their completion
+type WorkflowStateRequest struct {
+	ID       *int   `json:"id,omitempty" example:"3"`
+	Name     string `json:"name" binding:"required,min=1,max=50" example:"In progress"`
+	Terminal bool   `json:"terminal" example:"false"`
+	WIPLimit *int   `json:"wip_limit,omitempty" binding:"omitempty,min=1" example:"3"`
+}
+
+// WorkflowTransitionRequest allows a move between two states, named as in the request
+type WorkflowTransitionRequest struct {
+	From string `json:"from" binding:"required" example:"In progress"`
+	To   string `json:"to" binding:"required" example:"Review"`
+}
+
+// BoardColumn is one state of a board with the number of todos in it and the first of them
+type BoardColumn struct {
+	State     *WorkflowState `json:"state"`
+	Count     int            `json:"count" example:"4"`
+	OverLimit bool           `json:"over_limit" example:"true"` // more todos than the state's WIP limit
+	Todos     []*Todo        `json:"todos"`
+}
+
+// Board groups the todos of a project with a workflow by status, in manual order
+type Board struct {
+	ProjectID int            `json:"project_id" example:"1"`
+	Columns   []*BoardColumn `json:"columns"`
+}
@@ -0,0 +1,146 @@
+package ordering
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid position key")
+)
+
+// digits are the key digits in ascending byte order, so keys compare correctly as
+// plain strings; the database column must use a byte-wise ("C") collation
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// MaxLength is the key length past which a list should be rebalanced right away
+// rather than waiting for a periodic rebalance
+const MaxLength = 64
+
+// Keys are fractional indexes: a key is read as the base-62 fraction 0.<digits>, so there
+// is always room for another key between two others and moving an item only rewrites
+// that item's key. Keys never end in '0', which keeps room before every key too
+
+// Validate checks that key is a non-empty key made of valid digits without a trailing '0'
+func Validate(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: empty key", ErrInvalidKey)
+	}
+	if strings.Trim(key, digits) != "" {
+		return fmt.Errorf("%w: %q contains characters other than 0-9, A-Z and a-z", ErrInvalidKey, key)
+	}
+	if key[len(key)-1] == digits[0] {
+		return fmt.Errorf("%w: %q ends in 0", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// Between returns a key that sorts after a and before b
+// An empty a means the start of the list and an empty b its end
+func Between(a, b string) (string, error) {
+	if a != "" {
+		if err := Validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := Validate(b); err != nil {
+			return "", err
+		}
+		if a >= b {
+			return "", fmt.Errorf("%w: %q does not sort before %q", ErrInvalidKey, a, b)
+		}
+	}
+	return midpoint(a, b), nil
+}
+
+// midpoint returns a key strictly between a and b, which must be valid and ordered
+func midpoint(a, b string) string {
+	// Keep the common prefix and look for room in the remainder
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	// a and b now differ in their first digit
+	low, high := 0, base
+	if a != "" {
+		low = strings.IndexByte(digits, a[0])
+	}
+	if b != "" {
+		high = strings.IndexByte(digits, b[0])
+	}
+	if high-low > 1 {
+		// Items are mostly added at either end of a list, so step to the next digit
+		// there instead of halving the gap; keys then grow a digit per ~30 additions
+		switch {
+		case a != "" && b == "":
+			return string(digits[low+1])
+		case a == "" && b != "":
+			return string(digits[high-1])
+		default:
+			return string(digits[(low+high)/2])
+		}
+	}
+
+	// The first digits are adjacent: b's first digit alone still sorts after a
+	if b != "" && len(b) > 1 {
+		return b[:1]
+	}
+
+	// Otherwise extend a with a key after the rest of a
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[low]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit of key at i, reading missing digits as '0'
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
+
+// Spread returns n ascending keys spaced evenly over the key space, as short as possible
+// It is used to rebalance lists whose keys have grown long after many moves
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	// Leave at least one free value between neighbours so the next move stays short
+	width, span := 1, base
+	for span < 2*(n+1) {
+		width++
+		span *= base
+	}
+	step := span / (n + 1)
+
+	keys := make([]string, n)
+	buf := make([]byte, width)
+	for i := range keys {
+		value := (i + 1) * step
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[value%base]
+			value /= base
+		}
+		keys[i] = strings.TrimRight(string(buf), digits[:1])
+	}
+
+	return keys
+}
@@ -0,0 +1,134 @@
+package ordering
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"empty list", "", ""},
+		{"before first", "", "V"},
+		{"after last", "V", ""},
+		{"wide gap", "1", "z"},
+		{"adjacent digits", "1", "2"},
+		{"common prefix", "V1", "V2"},
+		{"prefix of the other", "V", "V1"},
+		{"after last digit", "z", ""},
+		{"before first digit", "", "1"},
+		{"before a long key", "", "01"},
+		{"between long keys", "Vzzz", "W"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Between(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Between(%q, %q) returned error: %v", tt.a, tt.b, err)
+			}
+			if err := Validate(key); err != nil {
+				t.Fatalf("Between(%q, %q) = %q, which is invalid: %v", tt.a, tt.b, key, err)
+			}
+			if tt.a != "" && key <= tt.a {
+				t.Errorf("Between(%q, %q) = %q, want a key after %q", tt.a, tt.b, key, tt.a)
+			}
+			if tt.b != "" && key >= tt.b {
+				t.Errorf("Between(%q, %q) = %q, want a key before %q", tt.a, tt.b, key, tt.b)
+			}
+		})
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"equal keys", "V", "V"},
+		{"wrong order", "W", "V"},
+		{"trailing zero", "V0", ""},
+		{"invalid digit", "V-", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Between(tt.a, tt.b); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Between(%q, %q) error = %v, want ErrInvalidKey", tt.a, tt.b, err)
+			}
+		})
+	}
+}
+
+func TestBetweenRepeated(t *testing.T) {
+	tests := []struct {
+		name   string
+		next   func(keys []string) (string, string)
+		maxLen int
+	}{
+		{"appending", func(keys []string) (string, string) { return keys[len(keys)-1], "" }, 40},
+		{"prepending", func(keys []string) (string, string) { return "", keys[0] }, 40},
+		{"inserting after the first", func(keys []string) (string, string) { return keys[0], keys[1] }, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{"V", "W"}
+			for i := 0; i < 1000; i++ {
+				a, b := tt.next(keys)
+				key, err := Between(a, b)
+				if err != nil {
+					t.Fatalf("insert %d: Between(%q, %q) returned error: %v", i, a, b, err)
+				}
+				if (a != "" && key <= a) || (b != "" && key >= b) {
+					t.Fatalf("insert %d: Between(%q, %q) = %q is out of order", i, a, b, key)
+				}
+				if len(key) > tt.maxLen {
+					t.Fatalf("insert %d: key %q is longer than %d", i, key, tt.maxLen)
+				}
+
+				switch {
+				case b == "":
+					keys = append(keys, key)
+				case a == "":
+					keys = append([]string{key}, keys...)
+				default:
+					keys = append(keys[:1], append([]string{key}, keys[1:]...)...)
+				}
+			}
+		})
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{1, 2, 30, 61, 62, 1000, 100000} {
+		keys := Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+
+		for i, key := range keys {
+			if err := Validate(key); err != nil {
+				t.Fatalf("Spread(%d)[%d] = %q is invalid: %v", n, i, key, err)
+			}
+			if i > 0 && keys[i-1] >= key {
+				t.Fatalf("Spread(%d) is not ascending at %d: %q >= %q", n, i, keys[i-1], key)
+			}
+			if i > 0 {
+				if _, err := Between(keys[i-1], key); err != nil {
+					t.Fatalf("Spread(%d) leaves no room between %q and %q: %v", n, keys[i-1], key, err)
+				}
+			}
+		}
+
+		if n <= 30 && len(keys[n-1]) > 1 {
+			t.Errorf("Spread(%d) uses keys of %d digits, want 1", n, len(keys[n-1]))
+		}
+	}
+
+	if keys := Spread(0); keys != nil {
+		t.Errorf("Spread(0) = %v, want nil", keys)
+	}
+}
@@ -0,0 +1,86 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+)
+
+// MaxPreview caps how many occurrences a preview can list
+const MaxPreview = 100
+
+// Rule is a parsed RFC 5545 RRULE anchored at the first occurrence of its series
+// Occurrences are computed in the rule's timezone, so a todo due at 09:00 stays
+// due at 09:00 local time across DST changes
+type Rule struct {
+	rule *rrule.RRule
+}
+
+// Normalize trims a rule and strips an optional "RRULE:" prefix
+func Normalize(rule string) string {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	return strings.TrimPrefix(rule, "RRULE:")
+}
+
+// Parse parses the RRULE body of rule (e.g. FREQ=WEEKLY;BYDAY=MO,WE) starting at dtstart in loc
+// DTSTART is always taken from dtstart; UNTIL values without a timezone are read in loc
+func Parse(rule string, dtstart time.Time, loc *time.Location) (*Rule, error) {
+	rule = Normalize(rule)
+	if rule == "" || strings.Contains(rule, "\n") {
+		return nil, fmt.Errorf("%w: expected a single RRULE such as FREQ=WEEKLY;BYDAY=MO", ErrInvalidRule)
+	}
+
+	option, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if !option.Dtstart.IsZero() {
+		return nil, fmt.Errorf("%w: DTSTART is taken from the todo's due date", ErrInvalidRule)
+	}
+	// Todos repeating more than once an hour make no sense and would flood the list
+	if option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return nil, fmt.Errorf("%w: FREQ must be HOURLY or less frequent", ErrInvalidRule)
+	}
+
+	option.Dtstart = dtstart.In(loc)
+	parsed, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	return &Rule{rule: parsed}, nil
+}
+
+// String renders the rule without DTSTART, in canonical form
+func (r *Rule) String() string {
+	return r.rule.OrigOptions.RRuleString()
+}
+
+// After returns the first occurrence strictly after t, and false once the series has ended
+func (r *Rule) After(t time.Time) (time.Time, bool) {
+	next := r.rule.After(t, false)
+	return next, !next.IsZero()
+}
+
+// Next returns up to n occurrences strictly after t
+func (r *Rule) Next(t time.Time, n int) []time.Time {
+	occurrences := []time.Time{}
+	next := r.rule.Iterator()
+	for len(occurrences) < n {
+		occurrence, ok := next()
+		if !ok {
+			break
+		}
+		if occurrence.After(t) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// AttachmentRepository handles database operations for attachment metadata
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *AttachmentRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// attachmentColumns lists the columns scanAttachment reads, in order
+const attachmentColumns = "id, todo_id, filename, content_type, size, storage_key, uploaded_by, created_at"
+
+// scanAttachment reads a row of attachmentColumns
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	var uploadedBy sql.NullInt64
+
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TodoID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&uploadedBy,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment.UploadedBy = models.NullInt64ToPtr(uploadedBy)
+	return attachment, nil
+}
+
+// Create inserts attachment metadata and fills in its ID and creation time
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	query := `
+		INSERT INTO todo_attachments (todo_id, filename, content_type, size, storage_key, uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		attachment.TodoID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		models.NullInt64(attachment.UploadedBy),
+		time.Now(),
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an attachment of the given todo
+func (r *AttachmentRepository) GetByID(ctx context.Context, todoID, id int) (*models.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM todo_attachments WHERE id = $1 AND todo_id = $2"
+
+	attachment, err := scanAttachment(r.conn(ctx).QueryRowContext(ctx, query, id, todoID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// ListByTodo retrieves the attachments of a todo, oldest first
+func (r *AttachmentRepository) ListByTodo(ctx context.Context, todoID int) ([]*models.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM todo_attachments WHERE todo_id = $1 ORDER BY created_at, id"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// Delete removes attachment metadata; the blob has to be deleted separately
+func (r *AttachmentRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM todo_attachments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// CommentRepository handles database operations for todo comments
+type CommentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *CommentRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// commentSelect selects a comment together with its author, in the order scanComment reads them
+const commentSelect = `
+	SELECT c.id, c.todo_id, c.author_id, c.body, c.edited_at, c.created_at, c.updated_at,
+		u.username, u.email
+	FROM todo_comments c
+	LEFT JOIN users u ON u.id = c.author_id
+`
+
+// scanComment reads a row selected with commentSelect
+func scanComment(row rowScanner) (*models.Comment, error) {
+	comment := &models.Comment{}
+	var (
+		authorID        sql.NullInt64
+		username, email sql.NullString
+	)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.TodoID,
+		&authorID,
+		&comment.Body,
+		&comment.EditedAt,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&username,
+		&email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.AuthorID = models.NullInt64ToPtr(authorID)
+	if authorID.Valid {
+		comment.Author = &models.UserInfo{
+			ID:       int(authorID.Int64),
+			Username: username.String,
+			Email:    email.String,
+		}
+	}
+
+	return comment, nil
+}
+
+// Create inserts a new comment and returns it with its author
+func (r *CommentRepository) Create(ctx context.Context, todoID, authorID int, body string) (*models.Comment, error) {
+	query := `
+		INSERT INTO todo_comments (todo_id, author_id, body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id
+	`
+
+	var id int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, todoID, authorID, body, time.Now()).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID retrieves a single comment
+func (r *CommentRepository) GetByID(ctx context.Context, id int) (*models.Comment, error) {
+	comment, err := scanComment(r.conn(ctx).QueryRowContext(ctx, commentSelect+" WHERE c.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// ListByTodo retrieves a page of a todo's comments in posting order, with the total count
+func (r *CommentRepository) ListByTodo(ctx context.Context, todoID, offset, limit int) ([]*models.Comment, int, error) {
+	var totalCount int
+	countQuery := "SELECT COUNT(*) FROM todo_comments WHERE todo_id = $1"
+	if err := r.conn(ctx).QueryRowContext(ctx, countQuery, todoID).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	query := commentSelect + `
+		WHERE c.todo_id = $1
+		ORDER BY c.created_at, c.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return comments, totalCount, nil
+}
+
+// Update replaces the body of a comment and marks it as edited
+func (r *CommentRepository) Update(ctx context.Context, id int, body string) (*models.Comment, error) {
+	query := `
+		UPDATE todo_comments
+		SET body = $1, edited_at = $2, updated_at = $2
+		WHERE id = $3
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, body, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes a comment
+func (r *CommentRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM todo_comments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
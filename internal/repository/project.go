@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// ProjectRepository handles database operations for projects
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+// NewProjectRepository creates a new project repository
+func NewProjectRepository(db *sql.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *ProjectRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// projectSelect lists the columns scanProject reads, followed by the project's todo count
+const projectSelect = `
+	p.id, p.user_id, p.parent_id, p.name, p.color, p.archived, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM todos t WHERE t.project_id = p.id AND t.deleted_at IS NULL)
+`
+
+// scanProject reads a row selected with projectSelect, followed by any extra columns into extra
+func scanProject(row rowScanner, extra ...interface{}) (*models.Project, error) {
+	project := &models.Project{}
+	var parentID sql.NullInt64
+
+	dest := []interface{}{
+		&project.ID,
+		&project.UserID,
+		&parentID,
+		&project.Name,
+		&project.Color,
+		&project.Archived,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+		&project.TodoCount,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	project.ParentID = models.NullInt64ToPtr(parentID)
+	return project, nil
+}
+
+// Create inserts a new project into the database
+func (r *ProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	query := `
+		INSERT INTO projects (user_id, parent_id, name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, archived, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		project.UserID,
+		models.NullInt64(project.ParentID),
+		project.Name,
+		project.Color,
+		now,
+		now,
+	).Scan(&project.ID, &project.Archived, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a project owned by the given user
+func (r *ProjectRepository) GetByID(ctx context.Context, id, userID int) (*models.Project, error) {
+	query := `SELECT ` + projectSelect + ` FROM projects p WHERE p.id = $1 AND p.user_id = $2`
+
+	project, err := scanProject(r.conn(ctx).QueryRowContext(ctx, query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetAccessible retrieves a project the given user owns or that is shared with them
+func (r *ProjectRepository) GetAccessible(ctx context.Context, id, userID int) (*models.Project, error) {
+	query := `
+		SELECT ` + projectSelect + `
+		FROM projects p
+		WHERE p.id = $1 AND (p.user_id = $2
+			OR EXISTS (SELECT 1 FROM shares s WHERE s.project_id = p.id AND s.user_id = $2))
+	`
+
+	project, err := scanProject(r.conn(ctx).QueryRowContext(ctx, query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// ListByUser retrieves a user's projects alphabetically as a flat list;
+// clients rebuild the folder tree from parent_id
+func (r *ProjectRepository) ListByUser(ctx context.Context, userID int, includeArchived bool) ([]*models.Project, error) {
+	query := `
+		SELECT ` + projectSelect + `
+		FROM projects p
+		WHERE p.user_id = $1 AND ($2 OR NOT p.archived)
+		ORDER BY LOWER(p.name), p.id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*models.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// Update modifies a project owned by the given user
+func (r *ProjectRepository) Update(ctx context.Context, id, userID int, req *models.UpdateProjectRequest) (*models.Project, error) {
+	setClauses := []string{"updated_at = $1"}
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if req.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, *req.Name)
+		argIndex++
+	}
+
+	if req.Color != nil {
+		setClauses = append(setClauses, fmt.Sprintf("color = $%d", argIndex))
+		args = append(args, *req.Color)
+		argIndex++
+	}
+
+	if req.Archived != nil {
+		setClauses = append(setClauses, fmt.Sprintf("archived = $%d", argIndex))
+		args = append(args, *req.Archived)
+		argIndex++
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			setClauses = append(setClauses, "parent_id = NULL")
+		} else {
+			setClauses = append(setClauses, fmt.Sprintf("parent_id = $%d", argIndex))
+			args = append(args, *req.ParentID)
+			argIndex++
+		}
+	}
+
+	args = append(args, id, userID)
+
+	query := fmt.Sprintf(`
+		UPDATE projects
+		SET %s
+		WHERE id = $%d AND user_id = $%d
+	`, strings.Join(setClauses, ", "), argIndex, argIndex+1)
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, id, userID)
+}
+
+// Delete removes a project owned by the given user
+// With ProjectDeleteCascade its subprojects are deleted too and all their todos go to the trash;
+// with ProjectDeleteMove its todos go to the inbox and its subprojects move up to its parent
+func (r *ProjectRepository) Delete(ctx context.Context, id, userID int, mode models.ProjectDeleteMode) error {
+	return inTx(ctx, r.db, func(ctx context.Context) error {
+		var parentID sql.NullInt64
+		err := r.conn(ctx).QueryRowContext(ctx,
+			"SELECT parent_id FROM projects WHERE id = $1 AND user_id = $2 FOR UPDATE",
+			id, userID,
+		).Scan(&parentID)
+		if err == sql.ErrNoRows {
+			return sql.ErrNoRows
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		deleted := []int{id}
+		if mode == models.ProjectDeleteCascade {
+			subtree := `
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM projects WHERE id = $1
+					UNION ALL
+					SELECT p.id FROM projects p JOIN subtree s ON p.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			`
+			if deleted, err = queryIDs(ctx, r.conn(ctx), subtree, id); err != nil {
+				return fmt.Errorf("failed to list subprojects: %w", err)
+			}
+
+			trashTodos := `
+				UPDATE todos
+				SET deleted_at = $2, deleted_by = $3, version = version + 1
+				WHERE project_id = ANY($1) AND deleted_at IS NULL
+			`
+			if _, err := r.conn(ctx).ExecContext(ctx, trashTodos, pq.Array(deleted), time.Now(), userID); err != nil {
+				return fmt.Errorf("failed to trash project todos: %w", err)
+			}
+		} else {
+			reparent := "UPDATE projects SET parent_id = $1 WHERE parent_id = $2"
+			if _, err := r.conn(ctx).ExecContext(ctx, reparent, parentID, id); err != nil {
+				return fmt.Errorf("failed to move subprojects: %w", err)
+			}
+		}
+
+		// The todos of the deleted projects, including trashed ones, fall back to the inbox
+		if err := moveTodosToInbox(ctx, r.conn(ctx), deleted); err != nil {
+			return err
+		}
+
+		// Subprojects left under the project cascade with it
+		if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM projects WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// IsDescendant reports whether candidateID is projectID itself or nested anywhere below it
+func (r *ProjectRepository) IsDescendant(ctx context.Context, projectID, candidateID int) (bool, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM projects WHERE id = $1
+			UNION ALL
+			SELECT p.id FROM projects p JOIN subtree s ON p.parent_id = s.id
+		)
+		SELECT EXISTS(SELECT 1 FROM subtree WHERE id = $2)
+	`
+
+	var descendant bool
+	if err := r.conn(ctx).QueryRowContext(ctx, query, projectID, candidateID).Scan(&descendant); err != nil {
+		return false, fmt.Errorf("failed to check project hierarchy: %w", err)
+	}
+
+	return descendant, nil
+}
+
+// ListSharedWith retrieves the projects shared with a user alphabetically, with the role they were given
+func (r *ProjectRepository) ListSharedWith(ctx context.Context, userID int) ([]*models.SharedProject, error) {
+	query := `
+		SELECT ` + projectSelect + `, s.role
+		FROM shares s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.user_id = $1
+		ORDER BY LOWER(p.name), p.id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*models.SharedProject{}
+	for rows.Next() {
+		var role models.ShareRole
+		project, err := scanProject(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, &models.SharedProject{Project: *project, Role: role})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shared projects: %w", err)
+	}
+
+	return projects, nil
+}
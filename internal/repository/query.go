@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// whereBuilder accumulates WHERE conditions together with their positional arguments
+// so that dynamic filters never interpolate user input into SQL
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg registers a value and returns its placeholder ($1, $2, ...)
+func (w *whereBuilder) arg(value interface{}) string {
+	w.args = append(w.args, value)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+// where adds a condition; use arg to build its placeholders
+func (w *whereBuilder) where(condition string) {
+	w.conditions = append(w.conditions, condition)
+}
+
+// clause renders the WHERE clause, or an empty string when there are no conditions
+func (w *whereBuilder) clause() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
+
+// queryIDs runs a query selecting a single integer column and collects the values
+func queryIDs(ctx context.Context, db DBTX, query string, args ...interface{}) ([]int, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
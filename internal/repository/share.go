@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// ShareRepository handles database operations for shares of todos and projects
+type ShareRepository struct {
+	db *sql.DB
+}
+
+// NewShareRepository creates a new share repository
+func NewShareRepository(db *sql.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *ShareRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// shareColumns maps what can be shared to the column of shares that points at it
+var shareColumns = map[models.ShareKind]string{
+	models.ShareKindTodo:    "todo_id",
+	models.ShareKindProject: "project_id",
+}
+
+// shareColumn returns the column for kind, rejecting unknown kinds
+func shareColumn(kind models.ShareKind) (string, error) {
+	column, ok := shareColumns[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown share kind %q", kind)
+	}
+	return column, nil
+}
+
+// shareSelect selects a share together with its user, in the order scanShare reads them
+const shareSelect = `
+	SELECT s.id, s.todo_id, s.project_id, s.user_id, s.role, s.invited_by, s.created_at, s.updated_at,
+		u.username, u.email
+	FROM shares s
+	JOIN users u ON u.id = s.user_id
+`
+
+// scanShare reads a row selected with shareSelect
+func scanShare(row rowScanner) (*models.Share, error) {
+	share := &models.Share{User: &models.UserInfo{}}
+	var todoID, projectID, invitedBy sql.NullInt64
+
+	err := row.Scan(
+		&share.ID,
+		&todoID,
+		&projectID,
+		&share.UserID,
+		&share.Role,
+		&invitedBy,
+		&share.CreatedAt,
+		&share.UpdatedAt,
+		&share.User.Username,
+		&share.User.Email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	share.TodoID = models.NullInt64ToPtr(todoID)
+	share.ProjectID = models.NullInt64ToPtr(projectID)
+	share.InvitedBy = models.NullInt64ToPtr(invitedBy)
+	share.User.ID = share.UserID
+	return share, nil
+}
+
+// Upsert shares a todo or project with a user, or changes the role of an existing share
+func (r *ShareRepository) Upsert(ctx context.Context, kind models.ShareKind, targetID, userID int, role models.ShareRole, invitedBy *int) (*models.Share, error) {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// The conflict target has to repeat the predicate of the partial unique index
+	query := fmt.Sprintf(`
+		INSERT INTO shares (%[1]s, user_id, role, invited_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (%[1]s, user_id) WHERE %[1]s IS NOT NULL
+		DO UPDATE SET role = EXCLUDED.role
+		RETURNING id
+	`, column)
+
+	var id int
+	err = r.conn(ctx).QueryRowContext(ctx, query, targetID, userID, role, models.NullInt64(invitedBy), time.Now()).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save share: %w", err)
+	}
+
+	share, err := scanShare(r.conn(ctx).QueryRowContext(ctx, shareSelect+" WHERE s.id = $1", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	return share, nil
+}
+
+// List retrieves the shares of a todo or project, oldest first
+func (r *ShareRepository) List(ctx context.Context, kind models.ShareKind, targetID int) ([]*models.Share, error) {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query := shareSelect + " WHERE s." + column + " = $1 ORDER BY s.created_at, s.id"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []*models.Share{}
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// Delete revokes a user's share of a todo or project
+func (r *ShareRepository) Delete(ctx context.Context, kind models.ShareKind, targetID, userID int) error {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return err
+	}
+
+	query := "DELETE FROM shares WHERE " + column + " = $1 AND user_id = $2"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, targetID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// TodoRole returns the highest role a user was given on a todo, either directly or
+// through the project it is filed in; an empty role means it isn't shared with them
+func (r *ShareRepository) TodoRole(ctx context.Context, todoID int, projectID *int, userID int) (models.ShareRole, error) {
+	query := `
+		SELECT role
+		FROM shares
+		WHERE user_id = $1 AND (todo_id = $2 OR project_id = $3)
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID, todoID, models.NullInt64(projectID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get todo role: %w", err)
+	}
+	defer rows.Close()
+
+	return highestRole(rows)
+}
+
+// ProjectRole returns the role a user was given on a project; an empty role means it isn't shared with them
+func (r *ShareRepository) ProjectRole(ctx context.Context, projectID, userID int) (models.ShareRole, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT role FROM shares WHERE user_id = $1 AND project_id = $2", userID, projectID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project role: %w", err)
+	}
+	defer rows.Close()
+
+	return highestRole(rows)
+}
+
+// highestRole reads a column of roles and returns the one granting the most access
+func highestRole(rows *sql.Rows) (models.ShareRole, error) {
+	var highest models.ShareRole
+	for rows.Next() {
+		var role models.ShareRole
+		if err := rows.Scan(&role); err != nil {
+			return "", fmt.Errorf("failed to scan role: %w", err)
+		}
+		if role.Rank() > highest.Rank() {
+			highest = role
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return highest, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// ShareLinkRepository handles database operations for share links
+type ShareLinkRepository struct {
+	db *sql.DB
+}
+
+// NewShareLinkRepository creates a new share link repository
+func NewShareLinkRepository(db *sql.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *ShareLinkRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// shareLinkColumns lists the columns every share link query selects, in the order scanShareLink reads them
+const shareLinkColumns = `id, todo_id, project_id, password_hash, expires_at, view_count, last_viewed_at, created_by, created_at`
+
+// scanShareLink reads the shareLinkColumns of a row, returning the password hash separately
+func scanShareLink(row rowScanner) (*models.ShareLink, string, error) {
+	link := &models.ShareLink{}
+	var (
+		todoID, projectID sql.NullInt64
+		passwordHash      sql.NullString
+	)
+
+	err := row.Scan(
+		&link.ID,
+		&todoID,
+		&projectID,
+		&passwordHash,
+		&link.ExpiresAt,
+		&link.ViewCount,
+		&link.LastViewedAt,
+		&link.CreatedBy,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	link.TodoID = models.NullInt64ToPtr(todoID)
+	link.ProjectID = models.NullInt64ToPtr(projectID)
+	link.HasPassword = passwordHash.Valid
+	return link, passwordHash.String, nil
+}
+
+// Create stores a new share link for a todo or project; an empty passwordHash leaves it unprotected
+func (r *ShareLinkRepository) Create(ctx context.Context, kind models.ShareKind, targetID int, tokenHash, passwordHash string, expiresAt *time.Time, createdBy int) (*models.ShareLink, error) {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO share_links (%s, token_hash, password_hash, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING %s
+	`, column, shareLinkColumns)
+
+	link, _, err := scanShareLink(r.conn(ctx).QueryRowContext(ctx, query,
+		targetID,
+		tokenHash,
+		sql.NullString{String: passwordHash, Valid: passwordHash != ""},
+		expiresAt,
+		createdBy,
+		time.Now(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return link, nil
+}
+
+// GetByTokenHash retrieves the share link with the given token hash, together with its password hash
+func (r *ShareLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, string, error) {
+	query := "SELECT " + shareLinkColumns + " FROM share_links WHERE token_hash = $1"
+
+	link, passwordHash, err := scanShareLink(r.conn(ctx).QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	return link, passwordHash, nil
+}
+
+// List retrieves the share links of a todo or project, newest first
+func (r *ShareLinkRepository) List(ctx context.Context, kind models.ShareKind, targetID int) ([]*models.ShareLink, error) {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + shareLinkColumns + " FROM share_links WHERE " + column + " = $1 ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*models.ShareLink{}
+	for rows.Next() {
+		link, _, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share links: %w", err)
+	}
+
+	return links, nil
+}
+
+// Delete revokes a share link of a todo or project
+func (r *ShareLinkRepository) Delete(ctx context.Context, kind models.ShareKind, targetID, id int) error {
+	column, err := shareColumn(kind)
+	if err != nil {
+		return err
+	}
+
+	query := "DELETE FROM share_links WHERE id = $1 AND " + column + " = $2"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete share link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordView counts one view of a share link
+func (r *ShareLinkRepository) RecordView(ctx context.Context, id int) error {
+	query := "UPDATE share_links SET view_count = view_count + 1, last_viewed_at = $2 WHERE id = $1"
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to record share link view: %w", err)
+	}
+
+	return nil
+}
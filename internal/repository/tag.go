@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// TagRepository handles database operations for tags
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Create inserts a new tag into the database
+func (r *TagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	query := `
+		INSERT INTO tags (user_id, name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := r.db.QueryRowContext(ctx, query, tag.UserID, tag.Name, tag.Color, now, now).
+		Scan(&tag.ID, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tag owned by the given user, including how many todos carry it
+func (r *TagRepository) GetByID(ctx context.Context, id, userID int) (*models.Tag, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.color, t.created_at, t.updated_at,
+			(SELECT COUNT(*) FROM todo_tags tt
+				JOIN todos td ON td.id = tt.todo_id
+				WHERE tt.tag_id = t.id AND td.deleted_at IS NULL)
+		FROM tags t
+		WHERE t.id = $1 AND t.user_id = $2
+	`
+
+	tag := &models.Tag{}
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
+		&tag.ID,
+		&tag.UserID,
+		&tag.Name,
+		&tag.Color,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+		&tag.TodoCount,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// ListByUser retrieves all tags of a user alphabetically, including how many todos carry each
+func (r *TagRepository) ListByUser(ctx context.Context, userID int) ([]*models.Tag, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.color, t.created_at, t.updated_at, COUNT(td.id)
+		FROM tags t
+		LEFT JOIN todo_tags tt ON tt.tag_id = t.id
+		LEFT JOIN todos td ON td.id = tt.todo_id AND td.deleted_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY LOWER(t.name)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		tag := &models.Tag{}
+		err := rows.Scan(
+			&tag.ID,
+			&tag.UserID,
+			&tag.Name,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+			&tag.TodoCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Update modifies a tag owned by the given user
+func (r *TagRepository) Update(ctx context.Context, id, userID int, req *models.UpdateTagRequest) (*models.Tag, error) {
+	setClauses := []string{"updated_at = $1"}
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if req.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, *req.Name)
+		argIndex++
+	}
+
+	if req.Color != nil {
+		setClauses = append(setClauses, fmt.Sprintf("color = $%d", argIndex))
+		args = append(args, *req.Color)
+		argIndex++
+	}
+
+	args = append(args, id, userID)
+
+	query := fmt.Sprintf(`
+		UPDATE tags
+		SET %s
+		WHERE id = $%d AND user_id = $%d
+	`, strings.Join(setClauses, ", "), argIndex, argIndex+1)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, id, userID)
+}
+
+// Delete removes a tag owned by the given user; it is detached from all todos
+func (r *TagRepository) Delete(ctx context.Context, id, userID int) error {
+	query := "DELETE FROM tags WHERE id = $1 AND user_id = $2"
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ExistsByName checks if the user already has a tag with this name (case-insensitive),
+// ignoring the tag with excludeID so a tag can be renamed to a different casing of itself
+func (r *TagRepository) ExistsByName(ctx context.Context, userID int, name string, excludeID int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND id <> $3)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, name, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tag existence: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// TimeEntryRepository handles database operations for time entries
+type TimeEntryRepository struct {
+	db *sql.DB
+}
+
+// NewTimeEntryRepository creates a new time entry repository
+func NewTimeEntryRepository(db *sql.DB) *TimeEntryRepository {
+	return &TimeEntryRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *TimeEntryRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// entrySeconds is the length of a time entry in seconds; running timers count up to now
+const entrySeconds = "GREATEST(EXTRACT(EPOCH FROM COALESCE(e.ended_at, NOW()) - e.started_at), 0)"
+
+// timeEntrySelect selects time entries in the order scanTimeEntry reads them
+// The todos join lets filters refer to the entry's todo as t
+const timeEntrySelect = `
+	SELECT e.id, e.todo_id, e.user_id, e.started_at, e.ended_at, FLOOR(` + entrySeconds + `)::BIGINT,
+		e.note, e.created_at, e.updated_at
+	FROM time_entries e
+	JOIN todos t ON t.id = e.todo_id
+`
+
+// scanTimeEntry reads a row selected with timeEntrySelect
+func scanTimeEntry(row rowScanner) (*models.TimeEntry, error) {
+	entry := &models.TimeEntry{}
+	err := row.Scan(
+		&entry.ID,
+		&entry.TodoID,
+		&entry.UserID,
+		&entry.StartedAt,
+		&entry.EndedAt,
+		&entry.Seconds,
+		&entry.Note,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// buildTimeEntryFilter turns a filter into WHERE conditions on time_entries e joined with todos t
+func buildTimeEntryFilter(filter *models.TimeEntryFilter) *whereBuilder {
+	w := &whereBuilder{}
+	if filter == nil {
+		return w
+	}
+
+	if filter.UserID != nil {
+		w.where("e.user_id = " + w.arg(*filter.UserID))
+	}
+	if filter.TodoID != nil {
+		w.where("e.todo_id = " + w.arg(*filter.TodoID))
+	}
+	if filter.ProjectID != nil {
+		w.where("t.project_id = " + w.arg(*filter.ProjectID))
+	}
+	if filter.Inbox {
+		w.where("t.project_id IS NULL")
+	}
+	if filter.From != nil {
+		w.where("e.started_at >= " + w.arg(*filter.From))
+	}
+	if filter.To != nil {
+		w.where("e.started_at < " + w.arg(*filter.To))
+	}
+	if filter.Running != nil {
+		if *filter.Running {
+			w.where("e.ended_at IS NULL")
+		} else {
+			w.where("e.ended_at IS NOT NULL")
+		}
+	}
+
+	return w
+}
+
+// Start starts a timer for a user on a todo, stopping the timer they had running at the same instant
+func (r *TimeEntryRepository) Start(ctx context.Context, todoID, userID int, now time.Time) (*models.TimeEntry, error) {
+	var id int
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		// Serializes starts per user, so two concurrent starts can't both find no running timer
+		if _, err := r.conn(ctx).ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		stop := `
+			UPDATE time_entries
+			SET ended_at = GREATEST($2, started_at)
+			WHERE user_id = $1 AND ended_at IS NULL
+		`
+		if _, err := r.conn(ctx).ExecContext(ctx, stop, userID, now); err != nil {
+			return fmt.Errorf("failed to stop running timer: %w", err)
+		}
+
+		insert := `
+			INSERT INTO time_entries (todo_id, user_id, started_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $3, $3)
+			RETURNING id
+		`
+		if err := r.conn(ctx).QueryRowContext(ctx, insert, todoID, userID, now).Scan(&id); err != nil {
+			return fmt.Errorf("failed to start timer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Stop stops the timer a user has running on a todo; it returns nil when there is none
+func (r *TimeEntryRepository) Stop(ctx context.Context, todoID, userID int, now time.Time) (*models.TimeEntry, error) {
+	query := `
+		UPDATE time_entries
+		SET ended_at = GREATEST($3, started_at)
+		WHERE todo_id = $1 AND user_id = $2 AND ended_at IS NULL
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query, todoID, userID, now).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop timer: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Create inserts a finished time entry
+func (r *TimeEntryRepository) Create(ctx context.Context, todoID, userID int, startedAt, endedAt time.Time, note string) (*models.TimeEntry, error) {
+	query := `
+		INSERT INTO time_entries (todo_id, user_id, started_at, ended_at, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id
+	`
+
+	var id int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, todoID, userID, startedAt, endedAt, note, time.Now()).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to create time entry: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID retrieves a single time entry
+func (r *TimeEntryRepository) GetByID(ctx context.Context, id int) (*models.TimeEntry, error) {
+	entry, err := scanTimeEntry(r.conn(ctx).QueryRowContext(ctx, timeEntrySelect+" WHERE e.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// List retrieves a page of the time entries matching the filter, most recently started first,
+// with the total count
+func (r *TimeEntryRepository) List(ctx context.Context, filter *models.TimeEntryFilter, offset, limit int) ([]*models.TimeEntry, int, error) {
+	w := buildTimeEntryFilter(filter)
+
+	var totalCount int
+	countQuery := "SELECT COUNT(*) FROM time_entries e JOIN todos t ON t.id = e.todo_id " + w.clause()
+	if err := r.conn(ctx).QueryRowContext(ctx, countQuery, w.args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count time entries: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY e.started_at DESC, e.id DESC
+		LIMIT %s OFFSET %s
+	`, timeEntrySelect, w.clause(), w.arg(limit), w.arg(offset))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list time entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.TimeEntry{}
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan time entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating time entries: %w", err)
+	}
+
+	return entries, totalCount, nil
+}
+
+// Update replaces the times and note of a time entry; a nil endedAt keeps a timer running
+func (r *TimeEntryRepository) Update(ctx context.Context, id int, startedAt time.Time, endedAt *time.Time, note string) (*models.TimeEntry, error) {
+	query := `
+		UPDATE time_entries
+		SET started_at = $1, ended_at = $2, note = $3
+		WHERE id = $4
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, startedAt, endedAt, note, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update time entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes a time entry
+func (r *TimeEntryRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM time_entries WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete time entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// timeTotalGroups holds, per grouping, the key and name columns totals are grouped by and their order
+// Entries count towards the day they started on in the requested timezone
+var timeTotalGroups = map[models.TimeGrouping]struct {
+	key, name, order string
+}{
+	models.GroupByTodo:    {key: "e.todo_id", name: "t.title", order: "2, 1"},
+	models.GroupByProject: {key: "t.project_id", name: "COALESCE(p.name, '')", order: "2, 1"},
+	models.GroupByDay:     {key: "TO_CHAR(e.started_at AT TIME ZONE %s, 'YYYY-MM-DD')", name: "''", order: "1"},
+}
+
+// KnowsTimezone reports whether the database can count days in a timezone; its timezone
+// data is its own and may not have every zone Go knows
+func (r *TimeEntryRepository) KnowsTimezone(ctx context.Context, timezone string) (bool, error) {
+	var known bool
+	query := "SELECT EXISTS (SELECT 1 FROM pg_timezone_names WHERE name = $1)"
+	if err := r.conn(ctx).QueryRowContext(ctx, query, timezone).Scan(&known); err != nil {
+		return false, fmt.Errorf("failed to look up timezone: %w", err)
+	}
+	return known, nil
+}
+
+// Totals sums the time entries matching the filter by todo, project or day, with days
+// counted in the given IANA timezone
+func (r *TimeEntryRepository) Totals(ctx context.Context, filter *models.TimeEntryFilter, groupBy models.TimeGrouping, timezone string) ([]*models.TimeTotal, error) {
+	group, ok := timeTotalGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown time grouping %q", groupBy)
+	}
+
+	w := buildTimeEntryFilter(filter)
+	key := group.key
+	if groupBy == models.GroupByDay {
+		key = fmt.Sprintf(key, w.arg(timezone))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, %s, FLOOR(SUM(%s))::BIGINT
+		FROM time_entries e
+		JOIN todos t ON t.id = e.todo_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		%s
+		GROUP BY 1, 2
+		ORDER BY %s
+	`, key, group.name, entrySeconds, w.clause(), group.order)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total time entries: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []*models.TimeTotal{}
+	for rows.Next() {
+		total := &models.TimeTotal{}
+		var (
+			id  sql.NullInt64
+			day sql.NullString
+		)
+
+		groupKey := interface{}(&id)
+		if groupBy == models.GroupByDay {
+			groupKey = &day
+		}
+		if err := rows.Scan(groupKey, &total.Name, &total.Seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan time total: %w", err)
+		}
+
+		switch groupBy {
+		case models.GroupByTodo:
+			total.TodoID = models.NullInt64ToPtr(id)
+		case models.GroupByProject:
+			total.ProjectID = models.NullInt64ToPtr(id)
+		case models.GroupByDay:
+			total.Day = &day.String
+		}
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time totals: %w", err)
+	}
+
+	return totals, nil
+}
@@ -1,401 +1,885 @@
-package repository
-
-import (
-	"context"
-	"database/sql"
-	"fmt"
-	"strings"
-	"time"
-
-	"github.com/swusjask/todo-api/internal/models"
-)
-
-// TodoRepository handles all database operations for todos
-type TodoRepository struct {
-	db *sql.DB
-}
-
-// NewTodoRepository creates a new repository instance
-func NewTodoRepository(db *sql.DB) *TodoRepository {
-	return &TodoRepository{db: db}
-}
-
-// Create inserts a new todo into the database
-func (r *TodoRepository) Create(ctx context.Context, req *models.CreateTodoRequest) (*models.Todo, error) {
-	todo := &models.Todo{
-		Title:       req.Title,
-		Description: req.Description,
-		Completed:   false,
-	}
-
-	// Set audit fields from context
-	todo.BeforeCreate(ctx)
-
-	query := `
-		INSERT INTO todos (title, description, completed, created_at, updated_at, created_by, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-	`
-
-	err := r.db.QueryRowContext(ctx, query,
-		todo.Title,
-		todo.Description,
-		todo.Completed,
-		todo.CreatedAt,
-		todo.UpdatedAt,
-		models.NullInt64(todo.CreatedBy),
-		models.NullInt64(todo.UpdatedBy),
-	).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CompletedAt,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&todo.CreatedBy,
-		&todo.UpdatedBy,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create todo: %w", err)
-	}
-
-	return todo, nil
-}
-
-// GetByID retrieves a single todo
-func (r *TodoRepository) GetByID(ctx context.Context, id int) (*models.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-		FROM todos
-		WHERE id = $1
-	`
-
-	todo := &models.Todo{}
-	var createdBy, updatedBy sql.NullInt64
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CompletedAt,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&createdBy,
-		&updatedBy,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get todo: %w", err)
-	}
-
-	todo.CreatedBy = models.NullInt64ToPtr(createdBy)
-	todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
-
-	return todo, nil
-}
-
-// GetByIDWithUser retrieves a todo with user information
-func (r *TodoRepository) GetByIDWithUser(ctx context.Context, id int) (*models.TodoWithUser, error) {
-	query := `
-		SELECT 
-			t.id, t.title, t.description, t.completed, t.completed_at, 
-			t.created_at, t.updated_at, t.created_by, t.updated_by,
-			cu.id, cu.username, cu.email,
-			uu.id, uu.username, uu.email
-		FROM todos t
-		LEFT JOIN users cu ON t.created_by = cu.id
-		LEFT JOIN users uu ON t.updated_by = uu.id
-		WHERE t.id = $1
-	`
-
-	var (
-		todo                         = &models.TodoWithUser{}
-		createdBy, updatedBy         sql.NullInt64
-		createdByUser, updatedByUser struct {
-			ID       sql.NullInt64
-			Username sql.NullString
-			Email    sql.NullString
-		}
-	)
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CompletedAt,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&createdBy,
-		&updatedBy,
-		&createdByUser.ID,
-		&createdByUser.Username,
-		&createdByUser.Email,
-		&updatedByUser.ID,
-		&updatedByUser.Username,
-		&updatedByUser.Email,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get todo with user: %w", err)
-	}
-
-	todo.CreatedBy = models.NullInt64ToPtr(createdBy)
-	todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
-
-	if createdByUser.ID.Valid {
-		todo.CreatedByUser = &models.UserInfo{
-			ID:       int(createdByUser.ID.Int64),
-			Username: createdByUser.Username.String,
-			Email:    createdByUser.Email.String,
-		}
-	}
-
-	if updatedByUser.ID.Valid {
-		todo.UpdatedByUser = &models.UserInfo{
-			ID:       int(updatedByUser.ID.Int64),
-			Username: updatedByUser.Username.String,
-			Email:    updatedByUser.Email.String,
-		}
-	}
-
-	return todo, nil
-}
-
-// List retrieves todos with pagination
-func (r *TodoRepository) List(ctx context.Context, offset, limit int) ([]*models.Todo, int, error) {
-	// Get user from context to potentially filter by user
-	// userID := models.GetUserIDFromContext(ctx)
-
-	// Base query - you can modify this to filter by user if needed
-	countQuery := "SELECT COUNT(*) FROM todos"
-	listQuery := `
-		SELECT id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-		FROM todos
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-
-	// If you want to filter by user, uncomment these:
-	// if userID != nil {
-	//     countQuery = "SELECT COUNT(*) FROM todos WHERE created_by = $1"
-	//     listQuery = `
-	//         SELECT id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-	//         FROM todos
-	//         WHERE created_by = $3
-	//         ORDER BY created_at DESC
-	//         LIMIT $1 OFFSET $2
-	//     `
-	// }
-
-	var totalCount int
-	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&totalCount); err != nil {
-		return nil, 0, fmt.Errorf("failed to count todos: %w", err)
-	}
-
-	rows, err := r.db.QueryContext(ctx, listQuery, limit, offset)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list todos: %w", err)
-	}
-	defer rows.Close()
-
-	var todos []*models.Todo
-	for rows.Next() {
-		todo := &models.Todo{}
-		var createdBy, updatedBy sql.NullInt64
-
-		err := rows.Scan(
-			&todo.ID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CompletedAt,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan todo: %w", err)
-		}
-
-		todo.CreatedBy = models.NullInt64ToPtr(createdBy)
-		todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
-		todos = append(todos, todo)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating todos: %w", err)
-	}
-
-	return todos, totalCount, nil
-}
-
-// Update modifies an existing todo
-func (r *TodoRepository) Update(ctx context.Context, id int, req *models.UpdateTodoRequest) (*models.Todo, error) {
-	// First, get the existing todo
-	existing, err := r.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	if existing == nil {
-		return nil, nil
-	}
-
-	// Set audit fields
-	existing.BeforeUpdate(ctx)
-
-	// Build dynamic UPDATE query
-	setClauses := []string{"updated_at = $1", "updated_by = $2"}
-	args := []interface{}{existing.UpdatedAt, models.NullInt64(existing.UpdatedBy)}
-	argIndex := 3
-
-	if req.Title != nil {
-		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argIndex))
-		args = append(args, *req.Title)
-		argIndex++
-	}
-
-	if req.Description != nil {
-		setClauses = append(setClauses, fmt.Sprintf("description = $%d", argIndex))
-		args = append(args, *req.Description)
-		argIndex++
-	}
-
-	if req.Completed != nil {
-		setClauses = append(setClauses, fmt.Sprintf("completed = $%d", argIndex))
-		args = append(args, *req.Completed)
-		argIndex++
-
-		if *req.Completed {
-			setClauses = append(setClauses, fmt.Sprintf("completed_at = $%d", argIndex))
-			args = append(args, time.Now())
-			argIndex++
-		} else {
-			setClauses = append(setClauses, "completed_at = NULL")
-		}
-	}
-
-	args = append(args, id)
-
-	query := fmt.Sprintf(`
-		UPDATE todos
-		SET %s
-		WHERE id = $%d
-		RETURNING id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-	`, strings.Join(setClauses, ", "), argIndex)
-
-	todo := &models.Todo{}
-	var createdBy, updatedBy sql.NullInt64
-
-	err = r.db.QueryRowContext(ctx, query, args...).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CompletedAt,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&createdBy,
-		&updatedBy,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to update todo: %w", err)
-	}
-
-	todo.CreatedBy = models.NullInt64ToPtr(createdBy)
-	todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
-
-	return todo, nil
-}
-
-// Delete removes a todo from the database
-func (r *TodoRepository) Delete(ctx context.Context, id int) error {
-	// Optionally, you can check if the user has permission to delete
-	// by verifying created_by matches the current user
-
-	query := "DELETE FROM todos WHERE id = $1"
-
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete todo: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
-}
-
-// ListByUser retrieves todos created by a specific user
-func (r *TodoRepository) ListByUser(ctx context.Context, userID int, offset, limit int) ([]*models.Todo, int, error) {
-	countQuery := "SELECT COUNT(*) FROM todos WHERE created_by = $1"
-	var totalCount int
-	if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&totalCount); err != nil {
-		return nil, 0, fmt.Errorf("failed to count user todos: %w", err)
-	}
-
-	query := `
-		SELECT id, title, description, completed, completed_at, created_at, updated_at, created_by, updated_by
-		FROM todos
-		WHERE created_by = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list user todos: %w", err)
-	}
-	defer rows.Close()
-
-	var todos []*models.Todo
-	for rows.Next() {
-		todo := &models.Todo{}
-		var createdBy, updatedBy sql.NullInt64
-
-		err := rows.Scan(
-			&todo.ID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CompletedAt,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan todo: %w", err)
-		}
-
-		todo.CreatedBy = models.NullInt64ToPtr(createdBy)
-		todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
-		todos = append(todos, todo)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating todos: %w", err)
-	}
-
-	return todos, totalCount, nil
-}
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// TodoRepository handles all database operations for todos
+type TodoRepository struct {
+	db *sql.DB
+}
+
+// NewTodoRepository creates a new repository instance
+func NewTodoRepository(db *sql.DB) *TodoRepository {
+	return &TodoRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *TodoRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// WithTx runs fn in a transaction; repository calls made with the ctx it receives join it
+func (r *TodoRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, r.db, fn)
+}
+
+// WithSavepoint runs fn in a savepoint of the transaction in ctx; a failing fn is rolled back
+// on its own and the transaction can carry on
+func (r *TodoRepository) WithSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inSavepoint(ctx, r.db, fn)
+}
+
+// todoColumns lists the columns every todo query selects, in the order scanTodo reads them
+var todoColumns = []string{
+	"id", "title", "description", "completed", "completed_at",
+	"due_at", "start_at", "priority", "project_id", "parent_id", "assignee_id",
+	"recurrence_rule", "recurrence_tz", "recurrence_start", "series_id",
+	"deleted_at", "deleted_by", "version", "position", "status_id",
+	"created_at", "updated_at", "created_by", "updated_by",
+}
+
+// todoSelect renders todoColumns for a SELECT or RETURNING list,
+// qualified with a table alias when one is given
+func todoSelect(alias string) string {
+	if alias == "" {
+		return strings.Join(todoColumns, ", ")
+	}
+	return alias + "." + strings.Join(todoColumns, ", "+alias+".")
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTodo reads the todoColumns of a row, followed by any extra columns into extra
+func scanTodo(row rowScanner, extra ...interface{}) (*models.Todo, error) {
+	todo := &models.Todo{}
+	var (
+		projectID, parentID, assigneeID, seriesID sql.NullInt64
+		statusID                                  sql.NullInt64
+		deletedBy, createdBy, updatedBy           sql.NullInt64
+		recurrenceRule, recurrenceTZ              sql.NullString
+		recurrenceStart                           sql.NullTime
+	)
+
+	dest := []interface{}{
+		&todo.ID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Completed,
+		&todo.CompletedAt,
+		&todo.DueAt,
+		&todo.StartAt,
+		&todo.Priority,
+		&projectID,
+		&parentID,
+		&assigneeID,
+		&recurrenceRule,
+		&recurrenceTZ,
+		&recurrenceStart,
+		&seriesID,
+		&todo.DeletedAt,
+		&deletedBy,
+		&todo.Version,
+		&todo.Position,
+		&statusID,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+		&createdBy,
+		&updatedBy,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	todo.ProjectID = models.NullInt64ToPtr(projectID)
+	todo.ParentID = models.NullInt64ToPtr(parentID)
+	todo.AssigneeID = models.NullInt64ToPtr(assigneeID)
+	todo.SeriesID = models.NullInt64ToPtr(seriesID)
+	todo.StatusID = models.NullInt64ToPtr(statusID)
+	todo.DeletedBy = models.NullInt64ToPtr(deletedBy)
+	if recurrenceRule.Valid {
+		todo.Recurrence = &models.Recurrence{
+			Rule:     recurrenceRule.String,
+			Timezone: recurrenceTZ.String,
+			Start:    recurrenceStart.Time,
+		}
+	}
+	todo.CreatedBy = models.NullInt64ToPtr(createdBy)
+	todo.UpdatedBy = models.NullInt64ToPtr(updatedBy)
+
+	return todo, nil
+}
+
+// Create inserts a new todo into the database
+func (r *TodoRepository) Create(ctx context.Context, req *models.CreateTodoRequest) (*models.Todo, error) {
+	todo := &models.Todo{
+		Title:       req.Title,
+		Description: req.Description,
+		Completed:   false,
+		DueAt:       req.DueAt,
+		StartAt:     req.StartAt,
+		Priority:    req.Priority,
+		ProjectID:   req.ProjectID,
+		ParentID:    req.ParentID,
+		AssigneeID:  req.AssigneeID,
+		StatusID:    req.StatusID,
+	}
+
+	// Set audit fields from context
+	todo.BeforeCreate(ctx)
+	if req.CreatedBy != nil {
+		todo.CreatedBy = req.CreatedBy
+	}
+
+	query := `
+		INSERT INTO todos (title, description, completed, due_at, start_at, priority, project_id, parent_id, assignee_id, status_id, position, created_at, updated_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING ` + todoSelect("")
+
+	var created *models.Todo
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		// New todos go to the end of their list
+		position, err := r.nextPosition(ctx, todo.PositionScope())
+		if err != nil {
+			return err
+		}
+
+		created, err = scanTodo(r.conn(ctx).QueryRowContext(ctx, query,
+			todo.Title,
+			todo.Description,
+			todo.Completed,
+			todo.DueAt,
+			todo.StartAt,
+			todo.Priority,
+			models.NullInt64(todo.ProjectID),
+			models.NullInt64(todo.ParentID),
+			models.NullInt64(todo.AssigneeID),
+			models.NullInt64(todo.StatusID),
+			position,
+			todo.CreatedAt,
+			todo.UpdatedAt,
+			models.NullInt64(todo.CreatedBy),
+			models.NullInt64(todo.UpdatedBy),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to create todo: %w", err)
+		}
+
+		if err := r.syncStatuses(ctx, created); err != nil {
+			return err
+		}
+
+		// Tags belong to the todo's creator; anonymous todos can't carry any
+		if created.CreatedBy != nil && len(req.Tags) > 0 {
+			if err := r.setTodoTags(ctx, created.ID, *created.CreatedBy, req.Tags); err != nil {
+				return err
+			}
+		}
+
+		if err := r.hydrate(ctx, []*models.Todo{created}); err != nil {
+			return err
+		}
+
+		return r.recordRevisions(ctx, models.RevisionCreate, nil, []*models.Todo{created})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetByID retrieves a single todo
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) GetByID(ctx context.Context, id int, ownerID *int) (*models.Todo, error) {
+	query := `
+		SELECT ` + todoSelect("") + `
+		FROM todos
+		WHERE id = $1 AND ($2::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$2") + `) AND deleted_at IS NULL
+	`
+
+	todo, err := scanTodo(r.conn(ctx).QueryRowContext(ctx, query, id, models.NullInt64(ownerID)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+
+	if err := r.hydrate(ctx, []*models.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// GetByIDWithUser retrieves a todo with user information
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) GetByIDWithUser(ctx context.Context, id int, ownerID *int) (*models.TodoWithUser, error) {
+	query := `
+		SELECT ` + todoSelect("t") + `,
+			cu.id, cu.username, cu.email,
+			uu.id, uu.username, uu.email
+		FROM todos t
+		LEFT JOIN users cu ON t.created_by = cu.id
+		LEFT JOIN users uu ON t.updated_by = uu.id
+		WHERE t.id = $1 AND ($2::INTEGER IS NULL OR ` + todoVisibleTo("t", "$2") + `) AND t.deleted_at IS NULL
+	`
+
+	var createdByUser, updatedByUser struct {
+		ID       sql.NullInt64
+		Username sql.NullString
+		Email    sql.NullString
+	}
+
+	todo, err := scanTodo(r.conn(ctx).QueryRowContext(ctx, query, id, models.NullInt64(ownerID)),
+		&createdByUser.ID,
+		&createdByUser.Username,
+		&createdByUser.Email,
+		&updatedByUser.ID,
+		&updatedByUser.Username,
+		&updatedByUser.Email,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo with user: %w", err)
+	}
+
+	if err := r.hydrate(ctx, []*models.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	result := &models.TodoWithUser{Todo: *todo}
+
+	if createdByUser.ID.Valid {
+		result.CreatedByUser = &models.UserInfo{
+			ID:       int(createdByUser.ID.Int64),
+			Username: createdByUser.Username.String,
+			Email:    createdByUser.Email.String,
+		}
+	}
+
+	if updatedByUser.ID.Valid {
+		result.UpdatedByUser = &models.UserInfo{
+			ID:       int(updatedByUser.ID.Int64),
+			Username: updatedByUser.Username.String,
+			Email:    updatedByUser.Email.String,
+		}
+	}
+
+	return result, nil
+}
+
+// List retrieves todos matching the filter with pagination
+// total count reflects the filter, not just the returned page
+func (r *TodoRepository) List(ctx context.Context, filter *models.TodoFilter, sort []models.SortField, offset, limit int) ([]*models.Todo, int, error) {
+	totalCount, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	w := buildTodoFilter(filter)
+	listQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM todos
+		%s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, todoSelect(""), w.clause(), todoOrderBy(sort), w.arg(limit), w.arg(offset))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, listQuery, w.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list todos: %w", err)
+	}
+	defer rows.Close()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, 0, err
+	}
+
+	return todos, totalCount, nil
+}
+
+// ListAfter retrieves up to limit todos matching the filter that come after the cursor
+// in (created_at, id) descending order; a nil cursor starts from the newest todo
+// Unlike offset paging this stays stable while todos are being inserted
+func (r *TodoRepository) ListAfter(ctx context.Context, filter *models.TodoFilter, after *models.TodoCursor, limit int) ([]*models.Todo, error) {
+	w := buildTodoFilter(filter)
+	if after != nil {
+		w.where(fmt.Sprintf("(created_at, id) < (%s, %s)", w.arg(after.CreatedAt), w.arg(after.ID)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM todos
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, todoSelect(""), w.clause(), w.arg(limit))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list todos: %w", err)
+	}
+	defer rows.Close()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// highlightStart and highlightStop delimit matches in ts_headline output. They are control
+// characters stripped from the text beforehand, so they never come from user input
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// renderHighlight HTML-escapes ts_headline output and turns its match delimiters into <mark> tags
+func renderHighlight(headline string) string {
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(escaped)
+}
+
+// Search runs a full-text query against todos matching the filter, best matches first
+// The query uses web search syntax: quoted phrases, OR, and -negation are supported
+func (r *TodoRepository) Search(ctx context.Context, query string, filter *models.TodoFilter, offset, limit int) ([]*models.TodoSearchResult, int, error) {
+	w := &whereBuilder{}
+	tsQuery := fmt.Sprintf("websearch_to_tsquery('english', %s)", w.arg(query))
+
+	w.where("search_vector @@ " + tsQuery)
+	addTodoFilter(w, filter)
+
+	var totalCount int
+	countQuery := "SELECT COUNT(*) FROM todos " + w.clause()
+	if err := r.conn(ctx).QueryRowContext(ctx, countQuery, w.args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	delimiters := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, highlightStart, highlightStop)
+	searchQuery := fmt.Sprintf(`
+		SELECT %[1]s,
+			ts_rank_cd(search_vector, %[2]s) AS rank,
+			ts_headline('english', translate(title, %[6]s, ''), %[2]s, %[7]s),
+			ts_headline('english', translate(COALESCE(description, ''), %[6]s, ''), %[2]s, %[8]s)
+		FROM todos
+		%[3]s
+		ORDER BY rank DESC, id DESC
+		LIMIT %[4]s OFFSET %[5]s
+	`, todoSelect(""), tsQuery, w.clause(), w.arg(limit), w.arg(offset),
+		w.arg(highlightStart+highlightStop),
+		w.arg(delimiters+", HighlightAll=true"),
+		w.arg(delimiters+", MaxFragments=2, MaxWords=20, MinWords=5"))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, searchQuery, w.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search todos: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		results []*models.TodoSearchResult
+		todos   []*models.Todo
+	)
+	for rows.Next() {
+		result := &models.TodoSearchResult{}
+
+		todo, err := scanTodo(rows, &result.Rank, &result.TitleHighlight, &result.DescriptionHighlight)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		result.TitleHighlight = renderHighlight(result.TitleHighlight)
+		result.DescriptionHighlight = renderHighlight(result.DescriptionHighlight)
+		result.Todo = *todo
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	for _, result := range results {
+		todos = append(todos, &result.Todo)
+	}
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, 0, err
+	}
+
+	return results, totalCount, nil
+}
+
+// Count returns the number of todos matching the filter
+func (r *TodoRepository) Count(ctx context.Context, filter *models.TodoFilter) (int, error) {
+	w := buildTodoFilter(filter)
+	query := "SELECT COUNT(*) FROM todos " + w.clause()
+
+	var count int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, w.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count todos: %w", err)
+	}
+
+	return count, nil
+}
+
+// Update modifies an existing todo
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) Update(ctx context.Context, id int, ownerID *int, req *models.UpdateTodoRequest) (*models.Todo, error) {
+	// First, get the existing todo
+	existing, err := r.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	// Set audit fields
+	existing.BeforeUpdate(ctx)
+
+	// Build dynamic UPDATE query
+	setClauses := []string{"updated_at = $1", "updated_by = $2", "version = version + 1"}
+	args := []interface{}{existing.UpdatedAt, models.NullInt64(existing.UpdatedBy)}
+	argIndex := 3
+
+	if req.Title != nil {
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argIndex))
+		args = append(args, *req.Title)
+		argIndex++
+	}
+
+	if req.Description != nil {
+		setClauses = append(setClauses, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, *req.Description)
+		argIndex++
+	}
+
+	if req.Completed != nil {
+		setClauses = append(setClauses, fmt.Sprintf("completed = $%d", argIndex))
+		args = append(args, *req.Completed)
+		argIndex++
+
+		if *req.Completed {
+			// A todo that is already completed keeps its original completion time
+			setClauses = append(setClauses, fmt.Sprintf("completed_at = CASE WHEN completed THEN completed_at ELSE $%d END", argIndex))
+			args = append(args, time.Now())
+			argIndex++
+		} else {
+			setClauses = append(setClauses, "completed_at = NULL")
+		}
+	}
+
+	if req.DueAt != nil {
+		setClauses = append(setClauses, fmt.Sprintf("due_at = $%d", argIndex))
+		args = append(args, *req.DueAt)
+		argIndex++
+	} else if req.ClearDueAt {
+		setClauses = append(setClauses, "due_at = NULL")
+	}
+
+	if req.StartAt != nil {
+		setClauses = append(setClauses, fmt.Sprintf("start_at = $%d", argIndex))
+		args = append(args, *req.StartAt)
+		argIndex++
+	} else if req.ClearStartAt {
+		setClauses = append(setClauses, "start_at = NULL")
+	}
+
+	if req.Priority != nil {
+		setClauses = append(setClauses, fmt.Sprintf("priority = $%d", argIndex))
+		args = append(args, *req.Priority)
+		argIndex++
+	}
+
+	if req.ProjectID != nil {
+		if *req.ProjectID == 0 {
+			setClauses = append(setClauses, "project_id = NULL")
+		} else {
+			setClauses = append(setClauses, fmt.Sprintf("project_id = $%d", argIndex))
+			args = append(args, *req.ProjectID)
+			argIndex++
+		}
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			setClauses = append(setClauses, "parent_id = NULL")
+		} else {
+			setClauses = append(setClauses, fmt.Sprintf("parent_id = $%d", argIndex))
+			args = append(args, *req.ParentID)
+			argIndex++
+		}
+	}
+
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == 0 {
+			setClauses = append(setClauses, "assignee_id = NULL")
+		} else {
+			setClauses = append(setClauses, fmt.Sprintf("assignee_id = $%d", argIndex))
+			args = append(args, *req.AssigneeID)
+			argIndex++
+		}
+	}
+
+	if req.StatusID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("status_id = $%d", argIndex))
+		args = append(args, *req.StatusID)
+		argIndex++
+	}
+
+	args = append(args, id, models.NullInt64(ownerID), pq.Array(req.IfMatch))
+
+	query := fmt.Sprintf(`
+		UPDATE todos
+		SET %s
+		WHERE id = $%d AND ($%d::INTEGER IS NULL OR %s) AND deleted_at IS NULL
+			AND ($%d::INTEGER[] IS NULL OR version = ANY($%d))
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), argIndex, argIndex+1, todoVisibleTo("todos", fmt.Sprintf("$%d", argIndex+1)), argIndex+2, argIndex+2, todoSelect(""))
+
+	var todo *models.Todo
+	err = r.trackRevisions(ctx, models.RevisionUpdate, []int{id}, func(ctx context.Context) error {
+		var err error
+		todo, err = scanTodo(r.conn(ctx).QueryRowContext(ctx, query, args...))
+		if err == sql.ErrNoRows {
+			todo = nil
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update todo: %w", err)
+		}
+
+		// A new project or completion can take the todo into another workflow state
+		if err := r.syncStatuses(ctx, todo); err != nil {
+			return err
+		}
+
+		// A new project or parent takes the todo to the end of another list
+		if err := r.placeIfMoved(ctx, todo, existing.PositionScope()); err != nil {
+			return err
+		}
+
+		if req.Completed != nil && *req.Completed && req.CompleteSubtasks {
+			if err := r.completeSubtasks(ctx, todo.ID, todo.UpdatedAt, todo.UpdatedBy); err != nil {
+				return err
+			}
+		}
+
+		if req.Tags != nil && todo.CreatedBy != nil {
+			if err := r.setTodoTags(ctx, todo.ID, *todo.CreatedBy, *req.Tags); err != nil {
+				return err
+			}
+		}
+
+		return r.hydrate(ctx, []*models.Todo{todo})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// Delete moves a todo and all of its subtasks to the trash
+// When ownerID is set, todos that user can't see are treated as missing; whether they
+// may delete it is up to the caller. A non-nil ifMatch only deletes the todo while its version is one of those listed
+func (r *TodoRepository) Delete(ctx context.Context, id int, ownerID *int, ifMatch []int) error {
+	audit := &models.BaseModel{}
+	audit.SetUpdatedBy(ctx)
+
+	treeQuery := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM todos
+			WHERE id = $1 AND ($2::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$2") + `) AND deleted_at IS NULL
+				AND ($4::INTEGER[] IS NULL OR version = ANY($4))
+			UNION ALL
+			SELECT t.id, tree.depth + 1
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE tree.depth < $3 AND t.deleted_at IS NULL
+		)
+		SELECT id FROM tree
+	`
+
+	return inTx(ctx, r.db, func(ctx context.Context) error {
+		ids, err := queryIDs(ctx, r.conn(ctx), treeQuery, id, models.NullInt64(ownerID), maxTreeWalk, pq.Array(ifMatch))
+		if err != nil {
+			return fmt.Errorf("failed to delete todo: %w", err)
+		}
+		if len(ids) == 0 {
+			return sql.ErrNoRows
+		}
+
+		// The whole subtree shares one deleted_at, which is how Restore finds it again
+		return r.trackRevisions(ctx, models.RevisionDelete, ids, func(ctx context.Context) error {
+			query := "UPDATE todos SET deleted_at = $1, deleted_by = $2, version = version + 1 WHERE id = ANY($3) AND deleted_at IS NULL"
+			_, err := r.conn(ctx).ExecContext(ctx, query, time.Now(), models.NullInt64(audit.UpdatedBy), pq.Array(ids))
+			if err != nil {
+				return fmt.Errorf("failed to delete todo: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// ListByUser retrieves the todos a user created or is assigned
+func (r *TodoRepository) ListByUser(ctx context.Context, userID int, offset, limit int) ([]*models.Todo, int, error) {
+	return r.List(ctx, &models.TodoFilter{OwnerID: &userID}, models.DefaultTodoSort, offset, limit)
+}
+
+// scanTodoRows reads every row of a todo listing query
+func scanTodoRows(rows *sql.Rows) ([]*models.Todo, error) {
+	var todos []*models.Todo
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		todos = append(todos, todo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+// hydrate loads the derived fields the todos table doesn't store for a batch of todos,
+// with one query per field rather than one per todo
+func (r *TodoRepository) hydrate(ctx context.Context, todos []*models.Todo) error {
+	if err := r.attachTags(ctx, todos); err != nil {
+		return err
+	}
+	if err := r.attachSubtaskProgress(ctx, todos); err != nil {
+		return err
+	}
+	if err := r.attachCommentCounts(ctx, todos); err != nil {
+		return err
+	}
+	if err := r.attachAssignees(ctx, todos); err != nil {
+		return err
+	}
+	return r.attachBlocked(ctx, todos)
+}
+
+// todoSortColumn describes how a sortable field is ordered in SQL
+type todoSortColumn struct {
+	expr     string
+	nullable bool   // nullable columns keep NULLs last in both directions
+	then     string // secondary ordering that always follows expr, regardless of direction
+}
+
+// todoSortColumns maps the sortable fields in models.TodoSortFields to SQL
+// Non-nullable columns are rendered without NULLS LAST so they can use the btree indexes
+var todoSortColumns = map[string]todoSortColumn{
+	"id":           {expr: "id"},
+	"title":        {expr: "LOWER(title)"},
+	"created_at":   {expr: "created_at"},
+	"updated_at":   {expr: "updated_at"},
+	"completed_at": {expr: "completed_at", nullable: true},
+	"due_at":       {expr: "due_at", nullable: true},
+	"start_at":     {expr: "start_at", nullable: true},
+	"deleted_at":   {expr: "deleted_at", nullable: true},
+	"position":     {expr: "position"},
+	// Todos of equal priority are ordered by what is due soonest
+	"priority": {expr: "priority", then: "due_at ASC NULLS LAST"},
+}
+
+// todoOrderBy renders an ORDER BY list for the given sort
+// id is always appended as a tiebreaker so paging over equal keys is stable
+func todoOrderBy(sort []models.SortField) string {
+	if len(sort) == 0 {
+		sort = models.DefaultTodoSort
+	}
+
+	var (
+		clauses []string
+		hasID   bool
+		lastDir = "ASC"
+	)
+	for _, field := range sort {
+		column, ok := todoSortColumns[field.Field]
+		if !ok {
+			continue
+		}
+
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+
+		clause := column.expr + " " + direction
+		if column.nullable {
+			clause += " NULLS LAST"
+		}
+		if column.then != "" {
+			clause += ", " + column.then
+		}
+		clauses = append(clauses, clause)
+
+		hasID = hasID || field.Field == "id"
+		lastDir = direction
+	}
+
+	if !hasID {
+		clauses = append(clauses, "id "+lastDir)
+	}
+
+	return strings.Join(clauses, ", ")
+}
+
+// buildTodoFilter translates a TodoFilter into parameterized WHERE conditions
+func buildTodoFilter(filter *models.TodoFilter) *whereBuilder {
+	w := &whereBuilder{}
+	addTodoFilter(w, filter)
+	return w
+}
+
+// addTodoFilter appends the conditions of a TodoFilter to an existing builder
+func addTodoFilter(w *whereBuilder, filter *models.TodoFilter) {
+	if filter == nil {
+		filter = &models.TodoFilter{}
+	}
+
+	// Deleted todos only ever show up in the trash
+	if filter.Deleted {
+		w.where("deleted_at IS NOT NULL")
+	} else {
+		w.where("deleted_at IS NULL")
+	}
+
+	// Assignees see the todos assigned to them, but the trash only holds the todos the user
+	// could have deleted, and can restore: the ones they own
+	if filter.OwnerID != nil {
+		owner := w.arg(*filter.OwnerID)
+		if filter.Deleted {
+			w.where(todoOwnedBy("todos", owner))
+		} else {
+			w.where("(created_by = " + owner + " OR assignee_id = " + owner + ")")
+		}
+	}
+	if filter.CreatedBy != nil {
+		w.where("created_by = " + w.arg(*filter.CreatedBy))
+	}
+	if filter.Completed != nil {
+		w.where("completed = " + w.arg(*filter.Completed))
+	}
+	if filter.ProjectID != nil {
+		w.where("project_id = " + w.arg(*filter.ProjectID))
+	}
+	if filter.Inbox {
+		w.where("project_id IS NULL")
+	}
+	if filter.AssigneeID != nil {
+		w.where("assignee_id = " + w.arg(*filter.AssigneeID))
+	}
+	if filter.Unassigned {
+		w.where("assignee_id IS NULL")
+	}
+	if filter.StatusID != nil {
+		w.where("status_id = " + w.arg(*filter.StatusID))
+	}
+	if filter.ParentID != nil {
+		w.where("parent_id = " + w.arg(*filter.ParentID))
+	}
+	if filter.TopLevel {
+		w.where("parent_id IS NULL")
+	}
+	if filter.IsBlocked != nil {
+		if *filter.IsBlocked {
+			w.where(openBlockerCondition)
+		} else {
+			w.where("NOT " + openBlockerCondition)
+		}
+	}
+
+	if filter.CreatedAfter != nil {
+		w.where("created_at >= " + w.arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		w.where("created_at < " + w.arg(*filter.CreatedBefore))
+	}
+	if filter.UpdatedAfter != nil {
+		w.where("updated_at >= " + w.arg(*filter.UpdatedAfter))
+	}
+	if filter.UpdatedBefore != nil {
+		w.where("updated_at < " + w.arg(*filter.UpdatedBefore))
+	}
+	if filter.CompletedAfter != nil {
+		w.where("completed_at >= " + w.arg(*filter.CompletedAfter))
+	}
+	if filter.CompletedBefore != nil {
+		w.where("completed_at < " + w.arg(*filter.CompletedBefore))
+	}
+	if filter.DueAfter != nil {
+		w.where("due_at >= " + w.arg(*filter.DueAfter))
+	}
+	if filter.DueBefore != nil {
+		w.where("due_at < " + w.arg(*filter.DueBefore))
+	}
+	if len(filter.Priorities) > 0 {
+		ranks := make([]int64, len(filter.Priorities))
+		for i, priority := range filter.Priorities {
+			ranks[i] = int64(priority.Rank())
+		}
+		w.where("priority = ANY(" + w.arg(pq.Array(ranks)) + ")")
+	}
+	if len(filter.Tags) > 0 {
+		// Every tag must be present: count the distinct matching tags per todo
+		w.where(fmt.Sprintf(`id IN (
+			SELECT tt.todo_id FROM todo_tags tt
+			JOIN tags tg ON tg.id = tt.tag_id
+			WHERE LOWER(tg.name) = ANY(%s)
+			GROUP BY tt.todo_id
+			HAVING COUNT(DISTINCT tg.id) = %s
+		)`, w.arg(pq.Array(lowerAll(filter.Tags))), w.arg(len(filter.Tags))))
+	}
+	if len(filter.TagsAny) > 0 {
+		w.where(fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM todo_tags tt
+			JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.todo_id = todos.id AND LOWER(tg.name) = ANY(%s)
+		)`, w.arg(pq.Array(lowerAll(filter.TagsAny)))))
+	}
+
+	if filter.Query != "" {
+		pattern := w.arg("%" + escapeLike(filter.Query) + "%")
+		w.where(fmt.Sprintf("(title ILIKE %s OR description ILIKE %s)", pattern, pattern))
+	}
+}
+
+// lowerAll lowercases every string, for case-insensitive matching with = ANY
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
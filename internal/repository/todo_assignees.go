@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// attachAssignees sets AssignedToUser on a batch of todos with a single query
+func (r *TodoRepository) attachAssignees(ctx context.Context, todos []*models.Todo) error {
+	byAssignee := make(map[int][]*models.Todo)
+	for _, todo := range todos {
+		todo.AssignedToUser = nil
+		if todo.AssigneeID != nil {
+			byAssignee[*todo.AssigneeID] = append(byAssignee[*todo.AssigneeID], todo)
+		}
+	}
+	if len(byAssignee) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(byAssignee))
+	for id := range byAssignee {
+		ids = append(ids, id)
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT id, username, email FROM users WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load todo assignees: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &models.UserInfo{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email); err != nil {
+			return fmt.Errorf("failed to scan todo assignee: %w", err)
+		}
+		for _, todo := range byAssignee[user.ID] {
+			todo.AssignedToUser = user
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating todo assignees: %w", err)
+	}
+
+	return nil
+}
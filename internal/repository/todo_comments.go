@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// attachCommentCounts sets CommentCount on a batch of todos with a single query
+func (r *TodoRepository) attachCommentCounts(ctx context.Context, todos []*models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Todo, len(todos))
+	for _, todo := range todos {
+		todo.CommentCount = 0
+		byID[todo.ID] = todo
+	}
+
+	query := `
+		SELECT todo_id, COUNT(*)
+		FROM todo_comments
+		WHERE todo_id = ANY($1)
+		GROUP BY todo_id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoIDs(todos))
+	if err != nil {
+		return fmt.Errorf("failed to count todo comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID, count int
+		if err := rows.Scan(&todoID, &count); err != nil {
+			return fmt.Errorf("failed to scan comment count: %w", err)
+		}
+		if todo, ok := byID[todoID]; ok {
+			todo.CommentCount = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating comment counts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// openBlockerCondition matches rows of the todos table that are blocked by at least one open todo
+const openBlockerCondition = `EXISTS (
+	SELECT 1 FROM todo_dependencies d
+	JOIN todos b ON b.id = d.blocked_by_id
+	WHERE d.todo_id = todos.id AND NOT b.completed AND b.deleted_at IS NULL
+)`
+
+// AddDependency records that todoID is blocked by blockedByID; adding it twice is a no-op
+func (r *TodoRepository) AddDependency(ctx context.Context, todoID, blockedByID int) error {
+	query := `
+		INSERT INTO todo_dependencies (todo_id, blocked_by_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, todoID, blockedByID); err != nil {
+		return fmt.Errorf("failed to add dependency: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveDependency removes the record that todoID is blocked by blockedByID
+func (r *TodoRepository) RemoveDependency(ctx context.Context, todoID, blockedByID int) error {
+	query := "DELETE FROM todo_dependencies WHERE todo_id = $1 AND blocked_by_id = $2"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, todoID, blockedByID)
+	if err != nil {
+		return fmt.Errorf("failed to remove dependency: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListBlockers retrieves the todos that block a todo, open ones first
+func (r *TodoRepository) ListBlockers(ctx context.Context, id int) ([]*models.Todo, error) {
+	return r.listDependencies(ctx, `
+		SELECT `+todoSelect("t")+`
+		FROM todo_dependencies d
+		JOIN todos t ON t.id = d.blocked_by_id
+		WHERE d.todo_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.completed, t.created_at, t.id
+	`, id)
+}
+
+// ListBlocking retrieves the todos a todo blocks, open ones first
+func (r *TodoRepository) ListBlocking(ctx context.Context, id int) ([]*models.Todo, error) {
+	return r.listDependencies(ctx, `
+		SELECT `+todoSelect("t")+`
+		FROM todo_dependencies d
+		JOIN todos t ON t.id = d.todo_id
+		WHERE d.blocked_by_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.completed, t.created_at, t.id
+	`, id)
+}
+
+// listDependencies runs one of the dependency listing queries
+func (r *TodoRepository) listDependencies(ctx context.Context, query string, id int) ([]*models.Todo, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if todos == nil {
+		todos = []*models.Todo{}
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// DependsOn reports whether todoID is blocked by candidateID, directly or through a chain
+// of other blockers. Adding "candidateID is blocked by todoID" would then create a cycle
+func (r *TodoRepository) DependsOn(ctx context.Context, todoID, candidateID int) (bool, error) {
+	query := `
+		WITH RECURSIVE blockers AS (
+			SELECT blocked_by_id, 1 AS depth FROM todo_dependencies WHERE todo_id = $1
+			UNION
+			SELECT d.blocked_by_id, b.depth + 1
+			FROM todo_dependencies d
+			JOIN blockers b ON d.todo_id = b.blocked_by_id
+			WHERE b.depth < $3
+		)
+		SELECT EXISTS(SELECT 1 FROM blockers WHERE blocked_by_id = $2)
+	`
+
+	var depends bool
+	if err := r.conn(ctx).QueryRowContext(ctx, query, todoID, candidateID, maxTreeWalk).Scan(&depends); err != nil {
+		return false, fmt.Errorf("failed to check dependencies: %w", err)
+	}
+
+	return depends, nil
+}
+
+// CountOpenBlockers returns how many todos blocking a todo are not completed yet
+func (r *TodoRepository) CountOpenBlockers(ctx context.Context, id int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM todo_dependencies d
+		JOIN todos b ON b.id = d.blocked_by_id
+		WHERE d.todo_id = $1 AND NOT b.completed AND b.deleted_at IS NULL
+	`
+
+	var count int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count open blockers: %w", err)
+	}
+
+	return count, nil
+}
+
+// attachBlocked sets IsBlocked on all given todos with a single query
+func (r *TodoRepository) attachBlocked(ctx context.Context, todos []*models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Todo, len(todos))
+	for _, todo := range todos {
+		todo.IsBlocked = false
+		byID[todo.ID] = todo
+	}
+
+	query := `
+		SELECT DISTINCT d.todo_id
+		FROM todo_dependencies d
+		JOIN todos b ON b.id = d.blocked_by_id
+		WHERE d.todo_id = ANY($1) AND NOT b.completed AND b.deleted_at IS NULL
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoIDs(todos))
+	if err != nil {
+		return fmt.Errorf("failed to load blocked state: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID int
+		if err := rows.Scan(&todoID); err != nil {
+			return fmt.Errorf("failed to scan blocked state: %w", err)
+		}
+		if todo, ok := byID[todoID]; ok {
+			todo.IsBlocked = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating blocked state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/ordering"
+)
+
+// positionScope renders a condition matching the todos of a models.PositionScope
+// bound to the three given parameters, in ProjectID, ParentID, OwnerID order
+// Trashed todos are included so restoring one can't collide with the keys handed out meanwhile
+func positionScope(project, parent, owner string) string {
+	return fmt.Sprintf(`project_id IS NOT DISTINCT FROM %[1]s::INTEGER
+		AND parent_id IS NOT DISTINCT FROM %[2]s::INTEGER
+		AND (project_id IS NOT NULL OR created_by IS NOT DISTINCT FROM %[3]s::INTEGER)`, project, parent, owner)
+}
+
+// positionScopeArgs returns the parameters positionScope expects for scope
+func positionScopeArgs(scope models.PositionScope) []interface{} {
+	return []interface{}{
+		models.NullInt64(scope.ProjectID),
+		models.NullInt64(scope.ParentID),
+		models.NullInt64(scope.OwnerID),
+	}
+}
+
+// nextPosition returns a key that places a new todo at the end of its list
+// A list whose keys have grown too long is rebalanced first
+func (r *TodoRepository) nextPosition(ctx context.Context, scope models.PositionScope) (string, error) {
+	last, err := lastPosition(ctx, r.conn(ctx), scope, nil)
+	if err != nil {
+		return "", err
+	}
+
+	position, err := ordering.Between(last, "")
+	if err != nil || len(position) <= ordering.MaxLength {
+		return position, err
+	}
+
+	if err := r.Rebalance(ctx, scope); err != nil {
+		return "", err
+	}
+	if last, err = lastPosition(ctx, r.conn(ctx), scope, nil); err != nil {
+		return "", err
+	}
+	return ordering.Between(last, "")
+}
+
+// lastPosition returns the last key of a list, ignoring the todos in exclude, or "" for an empty list
+func lastPosition(ctx context.Context, db DBTX, scope models.PositionScope, exclude []int) (string, error) {
+	query := `
+		SELECT position
+		FROM todos
+		WHERE ` + positionScope("$1", "$2", "$3") + ` AND NOT (id = ANY($4))
+		ORDER BY position DESC
+		LIMIT 1
+	`
+
+	ids := make([]int64, 0, len(exclude))
+	for _, id := range exclude {
+		ids = append(ids, int64(id))
+	}
+
+	var last string
+	err := db.QueryRowContext(ctx, query, append(positionScopeArgs(scope), pq.Array(ids))...).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get last position: %w", err)
+	}
+
+	return last, nil
+}
+
+// appendToList gives the todos with the given IDs, which have just joined a list, keys after
+// the rest of it in the order listed; a list whose keys grow too long is rebalanced
+func appendToList(ctx context.Context, db DBTX, scope models.PositionScope, ids []int) error {
+	last, err := lastPosition(ctx, db, scope, ids)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(ids))
+	for i := range ids {
+		if last, err = ordering.Between(last, ""); err != nil {
+			return err
+		}
+		keys[i] = last
+	}
+
+	update := `
+		UPDATE todos
+		SET position = k.position
+		FROM UNNEST($1::INTEGER[], $2::TEXT[]) AS k(id, position)
+		WHERE todos.id = k.id
+	`
+	if _, err := db.ExecContext(ctx, update, pq.Array(ids), pq.Array(keys)); err != nil {
+		return fmt.Errorf("failed to position todos: %w", err)
+	}
+
+	if len(last) > ordering.MaxLength {
+		return rebalanceList(ctx, db, scope)
+	}
+	return nil
+}
+
+// placeIfMoved puts a todo that an update took to another list at the end of that list
+func (r *TodoRepository) placeIfMoved(ctx context.Context, todo *models.Todo, previous models.PositionScope) error {
+	scope := todo.PositionScope()
+	if scope.Equal(previous) {
+		return nil
+	}
+
+	if err := appendToList(ctx, r.conn(ctx), scope, []int{todo.ID}); err != nil {
+		return err
+	}
+
+	return r.conn(ctx).QueryRowContext(ctx, "SELECT position FROM todos WHERE id = $1", todo.ID).Scan(&todo.Position)
+}
+
+// moveTodosToInbox takes the todos of the given projects, trashed ones included, out to their
+// creators' inboxes, keeping their order and placing them after what each inbox already holds
+func moveTodosToInbox(ctx context.Context, db DBTX, projectIDs []int) error {
+	query := `
+		SELECT id, parent_id, created_by
+		FROM todos
+		WHERE project_id = ANY($1)
+		ORDER BY project_id, parent_id NULLS FIRST, position, id
+		FOR UPDATE
+	`
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(projectIDs))
+	if err != nil {
+		return fmt.Errorf("failed to list project todos: %w", err)
+	}
+	defer rows.Close()
+
+	type inbox struct{ parentID, ownerID sql.NullInt64 }
+	var (
+		ids    []int
+		order  []inbox
+		groups = map[inbox][]int{}
+	)
+	for rows.Next() {
+		var (
+			id  int
+			key inbox
+		)
+		if err := rows.Scan(&id, &key.parentID, &key.ownerID); err != nil {
+			return fmt.Errorf("failed to scan project todo: %w", err)
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], id)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating project todos: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE todos SET project_id = NULL, version = version + 1 WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to move todos to the inbox: %w", err)
+	}
+
+	for _, key := range order {
+		scope := models.PositionScope{
+			ParentID: models.NullInt64ToPtr(key.parentID),
+			OwnerID:  models.NullInt64ToPtr(key.ownerID),
+		}
+		if err := appendToList(ctx, db, scope, groups[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AdjacentPosition returns the key of the todo right before (or, with next, right after) anchor
+// in its list, skipping the todo with ID skipID; an empty key means anchor is at that end of the list
+func (r *TodoRepository) AdjacentPosition(ctx context.Context, anchor *models.Todo, next bool, skipID int) (string, error) {
+	comparison, direction := "<", "DESC"
+	if next {
+		comparison, direction = ">", "ASC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT position
+		FROM todos
+		WHERE %s AND (position, id) %s ($4, $5) AND id <> $6
+		ORDER BY position %s, id %s
+		LIMIT 1
+	`, positionScope("$1", "$2", "$3"), comparison, direction, direction)
+
+	args := append(positionScopeArgs(anchor.PositionScope()), anchor.Position, anchor.ID, skipID)
+
+	var position string
+	err := r.conn(ctx).QueryRowContext(ctx, query, args...).Scan(&position)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get adjacent position: %w", err)
+	}
+
+	return position, nil
+}
+
+// SetPosition moves a todo to a new key in its list
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) SetPosition(ctx context.Context, id int, ownerID *int, position string) (*models.Todo, error) {
+	audit := &models.BaseModel{}
+	audit.BeforeUpdate(ctx)
+
+	query := `
+		UPDATE todos
+		SET position = $1, updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND ($5::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$5") + `) AND deleted_at IS NULL
+		RETURNING ` + todoSelect("")
+
+	todo, err := scanTodo(r.conn(ctx).QueryRowContext(ctx, query,
+		position,
+		audit.UpdatedAt,
+		models.NullInt64(audit.UpdatedBy),
+		id,
+		models.NullInt64(ownerID),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to move todo: %w", err)
+	}
+
+	if err := r.hydrate(ctx, []*models.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// ScopesToRebalance lists the lists holding a key longer than maxLength
+func (r *TodoRepository) ScopesToRebalance(ctx context.Context, maxLength int) ([]models.PositionScope, error) {
+	query := `
+		SELECT DISTINCT project_id, parent_id, CASE WHEN project_id IS NULL THEN created_by END
+		FROM todos
+		WHERE LENGTH(position) > $1
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lists to rebalance: %w", err)
+	}
+	defer rows.Close()
+
+	var scopes []models.PositionScope
+	for rows.Next() {
+		var projectID, parentID, ownerID sql.NullInt64
+		if err := rows.Scan(&projectID, &parentID, &ownerID); err != nil {
+			return nil, fmt.Errorf("failed to scan list: %w", err)
+		}
+		scopes = append(scopes, models.PositionScope{
+			ProjectID: models.NullInt64ToPtr(projectID),
+			ParentID:  models.NullInt64ToPtr(parentID),
+			OwnerID:   models.NullInt64ToPtr(ownerID),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lists: %w", err)
+	}
+
+	return scopes, nil
+}
+
+// Rebalance gives every todo in a list a short key, spread evenly, keeping their order
+// Only the keys change, so versions are left alone and pending conditional writes still apply
+func (r *TodoRepository) Rebalance(ctx context.Context, scope models.PositionScope) error {
+	return inTx(ctx, r.db, func(ctx context.Context) error {
+		return rebalanceList(ctx, r.conn(ctx), scope)
+	})
+}
+
+// rebalanceList does the work of Rebalance on db, which should be a transaction
+func rebalanceList(ctx context.Context, db DBTX, scope models.PositionScope) error {
+	query := `
+		SELECT id
+		FROM todos
+		WHERE ` + positionScope("$1", "$2", "$3") + `
+		ORDER BY position, id
+		FOR UPDATE
+	`
+
+	ids, err := queryIDs(ctx, db, query, positionScopeArgs(scope)...)
+	if err != nil {
+		return fmt.Errorf("failed to lock list: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	update := `
+		UPDATE todos
+		SET position = k.position
+		FROM UNNEST($1::INTEGER[], $2::TEXT[]) AS k(id, position)
+		WHERE todos.id = k.id
+	`
+
+	if _, err := db.ExecContext(ctx, update, pq.Array(ids), pq.Array(ordering.Spread(len(ids)))); err != nil {
+		return fmt.Errorf("failed to rebalance list: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// SetRecurrence sets or, with a nil rec, removes the recurrence rule of a todo
+// Setting a rule makes the todo part of seriesID, or starts a new series at the todo itself
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) SetRecurrence(ctx context.Context, id int, ownerID *int, rec *models.Recurrence, seriesID *int) (*models.Todo, error) {
+	audit := &models.BaseModel{}
+	audit.BeforeUpdate(ctx)
+
+	var rule, tz sql.NullString
+	var start sql.NullTime
+	if rec != nil {
+		rule = sql.NullString{String: rec.Rule, Valid: true}
+		tz = sql.NullString{String: rec.Timezone, Valid: true}
+		start = sql.NullTime{Time: rec.Start, Valid: true}
+	}
+
+	query := `
+		UPDATE todos
+		SET recurrence_rule = $1, recurrence_tz = $2, recurrence_start = $3,
+			series_id = CASE WHEN $1::TEXT IS NULL THEN series_id ELSE COALESCE($4, series_id, id) END,
+			updated_at = $5, updated_by = $6, version = version + 1
+		WHERE id = $7 AND ($8::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$8") + `) AND deleted_at IS NULL
+		RETURNING ` + todoSelect("")
+
+	todo, err := scanTodo(r.conn(ctx).QueryRowContext(ctx, query,
+		rule,
+		tz,
+		start,
+		models.NullInt64(seriesID),
+		audit.UpdatedAt,
+		models.NullInt64(audit.UpdatedBy),
+		id,
+		models.NullInt64(ownerID),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set recurrence: %w", err)
+	}
+
+	if err := r.hydrate(ctx, []*models.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// lockTodos loads todos by ID, trashed ones included, and locks their rows
+// until the surrounding transaction ends
+func (r *TodoRepository) lockTodos(ctx context.Context, ids []int) ([]*models.Todo, error) {
+	query := `
+		SELECT ` + todoSelect("") + `
+		FROM todos
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock todos: %w", err)
+	}
+	defer rows.Close()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// trackRevisions runs write in a transaction and records a revision for each of the
+// given todos that it changed, comparing their state before and after the write
+func (r *TodoRepository) trackRevisions(ctx context.Context, action models.RevisionAction, ids []int, write func(ctx context.Context) error) error {
+	return inTx(ctx, r.db, func(ctx context.Context) error {
+		before, err := r.lockTodos(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		if err := write(ctx); err != nil {
+			return err
+		}
+
+		after, err := r.lockTodos(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		return r.recordRevisions(ctx, action, before, after)
+	})
+}
+
+// recordRevisions stores one revision per todo in after whose tracked fields differ
+// from its counterpart in before; todos missing from before are recorded as new
+func (r *TodoRepository) recordRevisions(ctx context.Context, action models.RevisionAction, before, after []*models.Todo) error {
+	previous := make(map[int]*models.TodoSnapshot, len(before))
+	for _, todo := range before {
+		previous[todo.ID] = todo.Snapshot()
+	}
+
+	var todoIDs []int
+	var changes, snapshots []string
+	for _, todo := range after {
+		snapshot := todo.Snapshot()
+		diff, err := models.DiffSnapshots(previous[todo.ID], snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to diff todo %d: %w", todo.ID, err)
+		}
+		if len(diff) == 0 {
+			continue
+		}
+
+		encodedChanges, err := json.Marshal(diff)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision: %w", err)
+		}
+		encodedSnapshot, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision: %w", err)
+		}
+
+		todoIDs = append(todoIDs, todo.ID)
+		changes = append(changes, string(encodedChanges))
+		snapshots = append(snapshots, string(encodedSnapshot))
+	}
+
+	if len(todoIDs) == 0 {
+		return nil
+	}
+
+	// Writers hold the todo's row lock, so numbering revisions from MAX(rev) can't race
+	query := `
+		INSERT INTO todo_revisions (todo_id, rev, action, changes, snapshot, created_by, created_at)
+		SELECT c.todo_id,
+			COALESCE((SELECT MAX(rev) FROM todo_revisions WHERE todo_id = c.todo_id), 0) + 1,
+			$4, c.changes::JSONB, c.snapshot::JSONB, $5, $6
+		FROM UNNEST($1::INTEGER[], $2::TEXT[], $3::TEXT[]) AS c(todo_id, changes, snapshot)
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query,
+		pq.Array(todoIDs),
+		pq.Array(changes),
+		pq.Array(snapshots),
+		action,
+		models.NullInt64(models.GetUserIDFromContext(ctx)),
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record revisions: %w", err)
+	}
+
+	return nil
+}
+
+// ListRevisions retrieves a page of a todo's revisions, newest first, without snapshots
+func (r *TodoRepository) ListRevisions(ctx context.Context, todoID, offset, limit int) ([]*models.TodoRevision, int, error) {
+	var totalCount int
+	countQuery := "SELECT COUNT(*) FROM todo_revisions WHERE todo_id = $1"
+	if err := r.conn(ctx).QueryRowContext(ctx, countQuery, todoID).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	query := `
+		SELECT rev, todo_id, action, changes, created_by, created_at
+		FROM todo_revisions
+		WHERE todo_id = $1
+		ORDER BY rev DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.TodoRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, totalCount, nil
+}
+
+// GetRevision retrieves one revision of a todo together with its snapshot
+func (r *TodoRepository) GetRevision(ctx context.Context, todoID, rev int) (*models.TodoRevision, error) {
+	query := `
+		SELECT rev, todo_id, action, changes, created_by, created_at, snapshot
+		FROM todo_revisions
+		WHERE todo_id = $1 AND rev = $2
+	`
+
+	var snapshot []byte
+	revision, err := scanRevision(r.conn(ctx).QueryRowContext(ctx, query, todoID, rev), &snapshot)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	revision.Snapshot = &models.TodoSnapshot{}
+	if err := json.Unmarshal(snapshot, revision.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode revision snapshot: %w", err)
+	}
+
+	return revision, nil
+}
+
+// Revert overwrites a todo's tracked fields with a snapshot from its history
+// The trash state is left alone, and a state that left the todo's workflow falls back to the first fitting one
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) Revert(ctx context.Context, id int, ownerID *int, snapshot *models.TodoSnapshot) (*models.Todo, error) {
+	audit := &models.BaseModel{}
+	audit.BeforeUpdate(ctx)
+
+	query := `
+		UPDATE todos
+		SET title = $1, description = $2, completed = $3, completed_at = $4,
+			due_at = $5, start_at = $6, priority = $7, project_id = $8, parent_id = $9,
+			assignee_id = $10, updated_at = $11, updated_by = $12, version = version + 1,
+			status_id = (SELECT id FROM workflow_states WHERE id = $15 AND project_id = $8)
+		WHERE id = $13 AND ($14::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$14") + `) AND deleted_at IS NULL
+		RETURNING created_by
+	`
+
+	var reverted *models.Todo
+	err := r.trackRevisions(ctx, models.RevisionRevert, []int{id}, func(ctx context.Context) error {
+		previous, err := r.GetByID(ctx, id, nil)
+		if err != nil || previous == nil {
+			return err
+		}
+
+		var createdBy sql.NullInt64
+		err = r.conn(ctx).QueryRowContext(ctx, query,
+			snapshot.Title,
+			snapshot.Description,
+			snapshot.Completed,
+			snapshot.CompletedAt,
+			snapshot.DueAt,
+			snapshot.StartAt,
+			snapshot.Priority,
+			models.NullInt64(snapshot.ProjectID),
+			models.NullInt64(snapshot.ParentID),
+			models.NullInt64(snapshot.AssigneeID),
+			audit.UpdatedAt,
+			models.NullInt64(audit.UpdatedBy),
+			id,
+			models.NullInt64(ownerID),
+			models.NullInt64(snapshot.StatusID),
+		).Scan(&createdBy)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to revert todo: %w", err)
+		}
+
+		if createdBy.Valid {
+			if err := r.setTodoTags(ctx, id, int(createdBy.Int64), snapshot.Tags); err != nil {
+				return err
+			}
+		}
+
+		if _, err := syncStatuses(ctx, r.conn(ctx), "t.id = $1", id); err != nil {
+			return err
+		}
+
+		// The owner check already passed; the revert itself may have unassigned the caller
+		if reverted, err = r.GetByID(ctx, id, nil); err != nil {
+			return err
+		}
+		return r.placeIfMoved(ctx, reverted, previous.PositionScope())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reverted, nil
+}
+
+// scanRevision reads a revision row without its snapshot, followed by any extra columns
+func scanRevision(row rowScanner, extra ...interface{}) (*models.TodoRevision, error) {
+	revision := &models.TodoRevision{}
+	var (
+		changes   []byte
+		createdBy sql.NullInt64
+	)
+
+	dest := []interface{}{
+		&revision.Rev,
+		&revision.TodoID,
+		&revision.Action,
+		&changes,
+		&createdBy,
+		&revision.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return nil, err
+	}
+	revision.CreatedBy = models.NullInt64ToPtr(createdBy)
+
+	return revision, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// todoVisibleTo renders the condition under which a row of the todos table, referred to
+// as table, is visible to the user whose ID is the query parameter param: they created it,
+// are assigned to it, own its project, or it or its project is shared with them
+func todoVisibleTo(table, param string) string {
+	return strings.NewReplacer("{t}", table, "{u}", param).Replace(`({t}.created_by = {u} OR {t}.assignee_id = {u}
+		OR EXISTS (SELECT 1 FROM projects vp WHERE vp.id = {t}.project_id AND vp.user_id = {u})
+		OR EXISTS (SELECT 1 FROM shares vs WHERE vs.user_id = {u} AND (vs.todo_id = {t}.id OR vs.project_id = {t}.project_id)))`)
+}
+
+// todoOwnedBy renders, like todoVisibleTo, the condition under which the user owns a todo:
+// they created it or own its project
+func todoOwnedBy(table, param string) string {
+	return strings.NewReplacer("{t}", table, "{u}", param).Replace(`({t}.created_by = {u}
+		OR EXISTS (SELECT 1 FROM projects op WHERE op.id = {t}.project_id AND op.user_id = {u}))`)
+}
+
+// ListSharedWith retrieves the todos shared directly with a user, most recently shared first
+func (r *TodoRepository) ListSharedWith(ctx context.Context, userID int) ([]*models.SharedTodo, error) {
+	query := `
+		SELECT ` + todoSelect("t") + `, s.role
+		FROM shares s
+		JOIN todos t ON t.id = s.todo_id
+		WHERE s.user_id = $1 AND t.deleted_at IS NULL
+		ORDER BY s.created_at DESC, s.id DESC
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared todos: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		todos []*models.Todo
+		roles []models.ShareRole
+	)
+	for rows.Next() {
+		var role models.ShareRole
+		todo, err := scanTodo(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		todos = append(todos, todo)
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shared todos: %w", err)
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	shared := make([]*models.SharedTodo, len(todos))
+	for i, todo := range todos {
+		shared[i] = &models.SharedTodo{Todo: *todo, Role: roles[i]}
+	}
+
+	return shared, nil
+}
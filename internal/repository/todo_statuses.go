@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// syncStatuses puts the todos matching condition, which refers to them as t, into a state
+// of their project's workflow that agrees with their completion: a todo keeps its state when
+// it does, and moves to the first such state in board order otherwise. Todos outside a
+// project with a workflow lose their state. It returns the new state of each todo it changed
+func syncStatuses(ctx context.Context, db DBTX, condition string, args ...interface{}) (map[int]*int, error) {
+	query := `
+		WITH target AS (
+			SELECT t.id, (
+				SELECT s.id
+				FROM workflow_states s
+				WHERE s.project_id = t.project_id AND s.terminal = t.completed
+				ORDER BY (s.id = t.status_id) IS TRUE DESC, s.position, s.id
+				LIMIT 1
+			) AS status_id
+			FROM todos t
+			WHERE ` + condition + `
+		)
+		UPDATE todos
+		SET status_id = target.status_id
+		FROM target
+		WHERE todos.id = target.id AND todos.status_id IS DISTINCT FROM target.status_id
+		RETURNING todos.id, todos.status_id
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync todo statuses: %w", err)
+	}
+	defer rows.Close()
+
+	changed := map[int]*int{}
+	for rows.Next() {
+		var (
+			id       int
+			statusID sql.NullInt64
+		)
+		if err := rows.Scan(&id, &statusID); err != nil {
+			return nil, fmt.Errorf("failed to scan todo status: %w", err)
+		}
+		changed[id] = models.NullInt64ToPtr(statusID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating todo statuses: %w", err)
+	}
+
+	return changed, nil
+}
+
+// syncStatuses brings the states of the given todos in line with their project and completion,
+// updating them in place
+func (r *TodoRepository) syncStatuses(ctx context.Context, todos ...*models.Todo) error {
+	changed, err := syncStatuses(ctx, r.conn(ctx), "t.id = ANY($1)", todoIDs(todos))
+	if err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		if statusID, ok := changed[todo.ID]; ok {
+			todo.StatusID = statusID
+		}
+	}
+
+	return nil
+}
+
+// SyncProjectStatuses runs change, a change to a project's workflow, and moves the project's
+// todos into states of the new workflow like syncStatuses. Todos that end up in another state
+// than before the change get a new version and a revision; it returns the new state of each
+func (r *TodoRepository) SyncProjectStatuses(ctx context.Context, projectID int, change func(ctx context.Context) error) (map[int]*int, error) {
+	changed := map[int]*int{}
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		ids, err := queryIDs(ctx, r.conn(ctx), "SELECT id FROM todos WHERE project_id = $1", projectID)
+		if err != nil {
+			return fmt.Errorf("failed to list project todos: %w", err)
+		}
+
+		return r.trackRevisions(ctx, models.RevisionUpdate, ids, func(ctx context.Context) error {
+			// Removing a state clears it from its todos, so compare with the states from before the change
+			before, err := r.lockTodos(ctx, ids)
+			if err != nil {
+				return err
+			}
+			todoIDs := make([]int64, len(before))
+			statusIDs := make([]sql.NullInt64, len(before))
+			for i, todo := range before {
+				todoIDs[i] = int64(todo.ID)
+				statusIDs[i] = models.NullInt64(todo.StatusID)
+			}
+
+			if err := change(ctx); err != nil {
+				return err
+			}
+			if _, err := syncStatuses(ctx, r.conn(ctx), "t.project_id = $1", projectID); err != nil {
+				return err
+			}
+
+			query := `
+				UPDATE todos t
+				SET version = t.version + 1
+				FROM UNNEST($1::INTEGER[], $2::INTEGER[]) AS prior(id, status_id)
+				WHERE t.id = prior.id AND t.status_id IS DISTINCT FROM prior.status_id
+				RETURNING t.id, t.status_id
+			`
+			rows, err := r.conn(ctx).QueryContext(ctx, query, pq.Array(todoIDs), pq.Array(statusIDs))
+			if err != nil {
+				return fmt.Errorf("failed to update moved todos: %w", err)
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var (
+					id       int
+					statusID sql.NullInt64
+				)
+				if err := rows.Scan(&id, &statusID); err != nil {
+					return fmt.Errorf("failed to scan todo status: %w", err)
+				}
+				changed[id] = models.NullInt64ToPtr(statusID)
+			}
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("error iterating moved todos: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// CountInState counts the live todos in a workflow state
+// Writers enforcing a WIP limit lock the state first, so the count includes their own write
+func (r *TodoRepository) CountInState(ctx context.Context, stateID int) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM todos WHERE status_id = $1 AND deleted_at IS NULL"
+	if err := r.conn(ctx).QueryRowContext(ctx, query, stateID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count todos in state: %w", err)
+	}
+	return count, nil
+}
+
+// CountByState counts the live todos of a project in each of its workflow states
+func (r *TodoRepository) CountByState(ctx context.Context, projectID int) (map[int]int, error) {
+	query := `
+		SELECT status_id, COUNT(*)
+		FROM todos
+		WHERE project_id = $1 AND status_id IS NOT NULL AND deleted_at IS NULL
+		GROUP BY status_id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count todos by state: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	for rows.Next() {
+		var stateID, count int
+		if err := rows.Scan(&stateID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan state count: %w", err)
+		}
+		counts[stateID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating state counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// maxTreeWalk bounds every recursive walk of the subtask tree, so a corrupted
+// hierarchy can never make a query loop forever
+const maxTreeWalk = 100
+
+// todoIDs returns the IDs of todos as a Postgres array parameter
+func todoIDs(todos []*models.Todo) interface{} {
+	ids := make([]int64, len(todos))
+	for i, todo := range todos {
+		ids[i] = int64(todo.ID)
+	}
+	return pq.Array(ids)
+}
+
+// attachSubtaskProgress counts the direct subtasks of all given todos with a single query
+func (r *TodoRepository) attachSubtaskProgress(ctx context.Context, todos []*models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Todo, len(todos))
+	for _, todo := range todos {
+		todo.SubtaskProgress = nil
+		byID[todo.ID] = todo
+	}
+
+	query := `
+		SELECT parent_id, COUNT(*) FILTER (WHERE completed), COUNT(*)
+		FROM todos
+		WHERE parent_id = ANY($1) AND deleted_at IS NULL
+		GROUP BY parent_id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoIDs(todos))
+	if err != nil {
+		return fmt.Errorf("failed to load subtask progress: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parentID int
+			progress models.SubtaskProgress
+		)
+		if err := rows.Scan(&parentID, &progress.Done, &progress.Total); err != nil {
+			return fmt.Errorf("failed to scan subtask progress: %w", err)
+		}
+		if todo, ok := byID[parentID]; ok {
+			todo.SubtaskProgress = &progress
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating subtask progress: %w", err)
+	}
+
+	return nil
+}
+
+// ListChildren retrieves the direct subtasks of a todo, oldest first
+func (r *TodoRepository) ListChildren(ctx context.Context, parentID int) ([]*models.Todo, error) {
+	query := `
+		SELECT ` + todoSelect("") + `
+		FROM todos
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.hydrate(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// AttachSubtasks loads the whole subtask tree below each of the given todos, up to
+// maxDepth levels, with a single recursive query and nests it into their Subtasks
+func (r *TodoRepository) AttachSubtasks(ctx context.Context, todos []*models.Todo, maxDepth int) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		WITH RECURSIVE tree AS (
+			SELECT %[1]s, 1 AS depth
+			FROM todos
+			WHERE parent_id = ANY($1) AND deleted_at IS NULL
+			UNION ALL
+			SELECT %[2]s, tree.depth + 1
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE tree.depth < $2 AND t.deleted_at IS NULL
+		)
+		SELECT %[1]s
+		FROM tree
+		ORDER BY depth, created_at, id
+	`, todoSelect(""), todoSelect("t"))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoIDs(todos), maxDepth)
+	if err != nil {
+		return fmt.Errorf("failed to load subtask tree: %w", err)
+	}
+	defer rows.Close()
+
+	descendants, err := scanTodoRows(rows)
+	if err != nil {
+		return err
+	}
+
+	if err := r.hydrate(ctx, descendants); err != nil {
+		return err
+	}
+
+	// Rows come out level by level, so every parent is indexed before its children
+	byID := make(map[int]*models.Todo, len(todos)+len(descendants))
+	for _, todo := range todos {
+		todo.Subtasks = []*models.Todo{}
+		byID[todo.ID] = todo
+	}
+	for _, todo := range descendants {
+		todo.Subtasks = []*models.Todo{}
+		byID[todo.ID] = todo
+		if parent, ok := byID[*todo.ParentID]; ok {
+			parent.Subtasks = append(parent.Subtasks, todo)
+		}
+	}
+
+	return nil
+}
+
+// Depth returns how many ancestors a todo has; top-level todos have depth 0
+func (r *TodoRepository) Depth(ctx context.Context, id int) (int, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 0 AS depth FROM todos WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.parent_id, chain.depth + 1
+			FROM todos t
+			JOIN chain ON t.id = chain.parent_id
+			WHERE chain.depth < $2
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM chain
+	`
+
+	var depth int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, id, maxTreeWalk).Scan(&depth); err != nil {
+		return 0, fmt.Errorf("failed to get todo depth: %w", err)
+	}
+
+	return depth, nil
+}
+
+// Subtree reports how many levels of subtasks sit below a todo (0 without subtasks)
+// and whether candidateID is the todo itself or one of its subtasks at any depth
+func (r *TodoRepository) Subtree(ctx context.Context, id, candidateID int) (int, bool, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM todos WHERE id = $1
+			UNION ALL
+			SELECT t.id, tree.depth + 1
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE tree.depth < $3
+		)
+		SELECT COALESCE(MAX(depth), 0), COALESCE(BOOL_OR(id = $2), FALSE) FROM tree
+	`
+
+	var (
+		height   int
+		contains bool
+	)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, id, candidateID, maxTreeWalk).Scan(&height, &contains); err != nil {
+		return 0, false, fmt.Errorf("failed to inspect subtask tree: %w", err)
+	}
+
+	return height, contains, nil
+}
+
+// completeSubtasks completes every open subtask below a todo, at any depth
+func (r *TodoRepository) completeSubtasks(ctx context.Context, id int, updatedAt time.Time, updatedBy *int) error {
+	treeQuery := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM todos WHERE parent_id = $1
+			UNION ALL
+			SELECT t.id, tree.depth + 1
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE tree.depth < $2
+		)
+		SELECT t.id FROM todos t
+		JOIN tree ON tree.id = t.id
+		WHERE NOT t.completed AND t.deleted_at IS NULL
+	`
+
+	ids, err := queryIDs(ctx, r.conn(ctx), treeQuery, id, maxTreeWalk)
+	if err != nil {
+		return fmt.Errorf("failed to complete subtasks: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.trackRevisions(ctx, models.RevisionUpdate, ids, func(ctx context.Context) error {
+		query := `
+			UPDATE todos
+			SET completed = TRUE, completed_at = $1, updated_at = $1, updated_by = $2, version = version + 1
+			WHERE id = ANY($3) AND NOT completed
+		`
+		_, err := r.conn(ctx).ExecContext(ctx, query, updatedAt, models.NullInt64(updatedBy), pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to complete subtasks: %w", err)
+		}
+		_, err = syncStatuses(ctx, r.conn(ctx), "t.id = ANY($1)", pq.Array(ids))
+		return err
+	})
+}
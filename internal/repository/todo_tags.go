@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// setTodoTags replaces the tags of a todo with the named tags of userID,
+// creating any tag that doesn't exist yet. Names are matched case-insensitively
+// Call it inside a transaction together with the todo write
+func (r *TodoRepository) setTodoTags(ctx context.Context, todoID, userID int, names []string) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM todo_tags WHERE todo_id = $1", todoID); err != nil {
+		return fmt.Errorf("failed to clear todo tags: %w", err)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	createMissing := `
+		INSERT INTO tags (user_id, name)
+		SELECT $1, name FROM UNNEST($2::TEXT[]) AS name
+		ON CONFLICT (user_id, LOWER(name)) DO NOTHING
+	`
+	if _, err := r.conn(ctx).ExecContext(ctx, createMissing, userID, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	attach := `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, id FROM tags
+		WHERE user_id = $2 AND LOWER(name) IN (SELECT LOWER(name) FROM UNNEST($3::TEXT[]) AS name)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.conn(ctx).ExecContext(ctx, attach, todoID, userID, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to attach tags: %w", err)
+	}
+
+	return nil
+}
+
+// attachTags loads the tag names of all given todos with a single query
+// so listing a page of todos doesn't issue one query per todo
+func (r *TodoRepository) attachTags(ctx context.Context, todos []*models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Todo, len(todos))
+	for _, todo := range todos {
+		todo.Tags = []string{}
+		byID[todo.ID] = todo
+	}
+
+	query := `
+		SELECT tt.todo_id, t.name
+		FROM todo_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.todo_id = ANY($1)
+		ORDER BY LOWER(t.name)
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, todoIDs(todos))
+	if err != nil {
+		return fmt.Errorf("failed to load todo tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID int
+			name   string
+		)
+		if err := rows.Scan(&todoID, &name); err != nil {
+			return fmt.Errorf("failed to scan todo tag: %w", err)
+		}
+		if todo, ok := byID[todoID]; ok {
+			todo.Tags = append(todo.Tags, name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating todo tags: %w", err)
+	}
+
+	return nil
+}
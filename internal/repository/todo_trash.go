@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// GetDeleted retrieves a todo in the trash
+// When ownerID is set, todos that user can't see are treated as missing
+func (r *TodoRepository) GetDeleted(ctx context.Context, id int, ownerID *int) (*models.Todo, error) {
+	query := `
+		SELECT ` + todoSelect("") + `
+		FROM todos
+		WHERE id = $1 AND ($2::INTEGER IS NULL OR ` + todoVisibleTo("todos", "$2") + `) AND deleted_at IS NOT NULL
+	`
+
+	todo, err := scanTodo(r.conn(ctx).QueryRowContext(ctx, query, id, models.NullInt64(ownerID)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted todo: %w", err)
+	}
+
+	return todo, nil
+}
+
+// Restore brings a todo back from the trash together with the subtasks deleted along with it
+// A todo whose parent is still in the trash, or gone, is restored as a top-level todo
+func (r *TodoRepository) Restore(ctx context.Context, id int) (*models.Todo, error) {
+	var restored *models.Todo
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		var deletedAt time.Time
+		err := r.conn(ctx).QueryRowContext(ctx, `
+			SELECT deleted_at FROM todos
+			WHERE id = $1 AND deleted_at IS NOT NULL
+			FOR UPDATE
+		`, id).Scan(&deletedAt)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get deleted todo: %w", err)
+		}
+
+		treeQuery := `
+			WITH RECURSIVE tree AS (
+				SELECT id, 0 AS depth FROM todos WHERE id = $1
+				UNION ALL
+				SELECT t.id, tree.depth + 1
+				FROM todos t
+				JOIN tree ON t.parent_id = tree.id
+				WHERE tree.depth < $3 AND t.deleted_at = $2
+			)
+			SELECT id FROM tree
+		`
+		ids, err := queryIDs(ctx, r.conn(ctx), treeQuery, id, deletedAt, maxTreeWalk)
+		if err != nil {
+			return fmt.Errorf("failed to restore todo: %w", err)
+		}
+
+		err = r.trackRevisions(ctx, models.RevisionRestore, ids, func(ctx context.Context) error {
+			restore := "UPDATE todos SET deleted_at = NULL, deleted_by = NULL, version = version + 1 WHERE id = ANY($1)"
+			if _, err := r.conn(ctx).ExecContext(ctx, restore, pq.Array(ids)); err != nil {
+				return fmt.Errorf("failed to restore todo: %w", err)
+			}
+
+			detach := `
+				UPDATE todos
+				SET parent_id = NULL
+				WHERE id = $1 AND parent_id IN (SELECT id FROM todos WHERE deleted_at IS NOT NULL)
+			`
+			if _, err := r.conn(ctx).ExecContext(ctx, detach, id); err != nil {
+				return fmt.Errorf("failed to detach restored todo: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		restored, err = r.GetByID(ctx, id, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// Purge permanently deletes todos that have been in the trash since before the cutoff
+// It returns how many todos were purged and the storage keys of their attachments,
+// whose blobs the caller has to delete once the purge is committed
+func (r *TodoRepository) Purge(ctx context.Context, cutoff time.Time) (int64, []string, error) {
+	var (
+		purged int64
+		keys   []string
+	)
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		rows, err := r.conn(ctx).QueryContext(ctx, `
+			DELETE FROM todo_attachments a
+			USING todos t
+			WHERE a.todo_id = t.id AND t.deleted_at < $1
+			RETURNING a.storage_key
+		`, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to purge attachments: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				return fmt.Errorf("failed to scan attachment key: %w", err)
+			}
+			keys = append(keys, key)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating attachment keys: %w", err)
+		}
+
+		result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM todos WHERE deleted_at < $1", cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to purge deleted todos: %w", err)
+		}
+
+		purged, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return purged, keys, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is the part of *sql.DB and *sql.Tx the repositories use,
+// so the same query code runs inside and outside transactions
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txContextKey marks the transaction carried by a context
+type txContextKey struct{}
+
+// conn returns the transaction carried by ctx, or db when there is none
+func conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// inTx runs fn inside a transaction and commits it if fn succeeds
+// When ctx already carries a transaction, fn joins it and the outermost caller commits
+func inTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// inSavepoint runs fn inside a savepoint of the transaction carried by ctx, so that when fn
+// fails its writes are rolled back without aborting the rest of the transaction
+// Without a transaction in ctx, fn runs in a transaction of its own
+func inSavepoint(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	if !ok {
+		return inTx(ctx, db, fn)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT nested"); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT nested"); rollbackErr != nil {
+			return fmt.Errorf("failed to roll back savepoint: %w", rollbackErr)
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT nested"); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return nil
+}
@@ -22,8 +22,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 // Create inserts a new user into the database
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, first_name, last_name, is_active, is_admin, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (email, username, password_hash, first_name, last_name, is_active, is_admin, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -35,6 +35,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.LastName,
 		user.IsActive,
 		user.IsAdmin,
+		user.Timezone,
 		time.Now(),
 		time.Now(),
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
@@ -50,7 +51,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	query := `
 		SELECT id, email, username, password_hash, first_name, last_name, 
-		       is_active, is_admin, last_login_at, created_at, updated_at
+		       is_active, is_admin, timezone, last_login_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -65,6 +66,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, err
 		&user.LastName,
 		&user.IsActive,
 		&user.IsAdmin,
+		&user.Timezone,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -84,7 +86,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, err
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
 		SELECT id, email, username, password_hash, first_name, last_name, 
-		       is_active, is_admin, last_login_at, created_at, updated_at
+		       is_active, is_admin, timezone, last_login_at, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -99,6 +101,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 		&user.LastName,
 		&user.IsActive,
 		&user.IsAdmin,
+		&user.Timezone,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -118,7 +121,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
 		SELECT id, email, username, password_hash, first_name, last_name, 
-		       is_active, is_admin, last_login_at, created_at, updated_at
+		       is_active, is_admin, timezone, last_login_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -133,6 +136,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 		&user.LastName,
 		&user.IsActive,
 		&user.IsAdmin,
+		&user.Timezone,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -160,6 +164,18 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID int) error
 	return nil
 }
 
+// UpdateTimezone changes the user's timezone
+func (r *UserRepository) UpdateTimezone(ctx context.Context, userID int, timezone string) error {
+	query := `UPDATE users SET timezone = $1 WHERE id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, timezone, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update timezone: %w", err)
+	}
+
+	return nil
+}
+
 // ExistsByEmail checks if a user exists with the given email
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
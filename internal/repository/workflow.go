@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// WorkflowRepository handles database operations for project workflows
+type WorkflowRepository struct {
+	db *sql.DB
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *sql.DB) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// conn returns the connection queries should run on, honouring a transaction in ctx
+func (r *WorkflowRepository) conn(ctx context.Context) DBTX {
+	return conn(ctx, r.db)
+}
+
+// Get retrieves the workflow of a project; a project without one has no states
+func (r *WorkflowRepository) Get(ctx context.Context, projectID int) (*models.Workflow, error) {
+	statesQuery := `
+		SELECT id, project_id, name, terminal, wip_limit, position, created_at, updated_at
+		FROM workflow_states
+		WHERE project_id = $1
+		ORDER BY position, id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, statesQuery, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow states: %w", err)
+	}
+	defer rows.Close()
+
+	workflow := &models.Workflow{
+		ProjectID:   projectID,
+		States:      []*models.WorkflowState{},
+		Transitions: []models.WorkflowTransition{},
+	}
+	for rows.Next() {
+		state := &models.WorkflowState{}
+		var wipLimit sql.NullInt64
+		err := rows.Scan(
+			&state.ID,
+			&state.ProjectID,
+			&state.Name,
+			&state.Terminal,
+			&wipLimit,
+			&state.Position,
+			&state.CreatedAt,
+			&state.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workflow state: %w", err)
+		}
+		state.WIPLimit = models.NullInt64ToPtr(wipLimit)
+		workflow.States = append(workflow.States, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workflow states: %w", err)
+	}
+
+	transitionsQuery := `
+		SELECT t.from_state_id, t.to_state_id
+		FROM workflow_transitions t
+		JOIN workflow_states s ON s.id = t.from_state_id
+		WHERE s.project_id = $1
+		ORDER BY t.from_state_id, t.to_state_id
+	`
+
+	transitions, err := r.conn(ctx).QueryContext(ctx, transitionsQuery, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow transitions: %w", err)
+	}
+	defer transitions.Close()
+
+	for transitions.Next() {
+		var transition models.WorkflowTransition
+		if err := transitions.Scan(&transition.From, &transition.To); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow transition: %w", err)
+		}
+		workflow.Transitions = append(workflow.Transitions, transition)
+	}
+	if err := transitions.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workflow transitions: %w", err)
+	}
+
+	return workflow, nil
+}
+
+// LockState retrieves a workflow state and locks it until the transaction in ctx ends,
+// so writers counting the todos in it take turns; it returns nil if the state doesn't exist
+func (r *WorkflowRepository) LockState(ctx context.Context, id int) (*models.WorkflowState, error) {
+	query := `
+		SELECT id, project_id, name, terminal, wip_limit, position, created_at, updated_at
+		FROM workflow_states
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	state := &models.WorkflowState{}
+	var wipLimit sql.NullInt64
+	err := r.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&state.ID,
+		&state.ProjectID,
+		&state.Name,
+		&state.Terminal,
+		&wipLimit,
+		&state.Position,
+		&state.CreatedAt,
+		&state.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock workflow state: %w", err)
+	}
+	state.WIPLimit = models.NullInt64ToPtr(wipLimit)
+
+	return state, nil
+}
+
+// Replace stores the states of a project's workflow, in board order, and the transitions between
+// them, given as pairs of indexes into states. States with an ID are updated in place and the
+// project's other states are removed; moving the project's todos into matching states is left
+// to TodoRepository.SyncProjectStatuses, in the same transaction
+func (r *WorkflowRepository) Replace(ctx context.Context, projectID int, states []*models.WorkflowState, transitions [][2]int) error {
+	return inTx(ctx, r.db, func(ctx context.Context) error {
+		kept := []int64{}
+		for _, state := range states {
+			if state.ID != 0 {
+				kept = append(kept, int64(state.ID))
+			}
+		}
+
+		// Transitions of removed states go with them
+		deleteQuery := "DELETE FROM workflow_states WHERE project_id = $1 AND NOT (id = ANY($2))"
+		if _, err := r.conn(ctx).ExecContext(ctx, deleteQuery, projectID, pq.Array(kept)); err != nil {
+			return fmt.Errorf("failed to remove workflow states: %w", err)
+		}
+
+		transitionsQuery := `
+			DELETE FROM workflow_transitions
+			WHERE from_state_id IN (SELECT id FROM workflow_states WHERE project_id = $1)
+		`
+		if _, err := r.conn(ctx).ExecContext(ctx, transitionsQuery, projectID); err != nil {
+			return fmt.Errorf("failed to remove workflow transitions: %w", err)
+		}
+
+		updateQuery := `
+			UPDATE workflow_states
+			SET name = $1, terminal = $2, wip_limit = $3, position = $4
+			WHERE id = $5 AND project_id = $6
+		`
+		insertQuery := `
+			INSERT INTO workflow_states (project_id, name, terminal, wip_limit, position)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		for position, state := range states {
+			wipLimit := models.NullInt64(state.WIPLimit)
+			if state.ID == 0 {
+				err := r.conn(ctx).QueryRowContext(ctx, insertQuery, projectID, state.Name, state.Terminal, wipLimit, position).Scan(&state.ID)
+				if err != nil {
+					return fmt.Errorf("failed to create workflow state: %w", err)
+				}
+				continue
+			}
+
+			result, err := r.conn(ctx).ExecContext(ctx, updateQuery, state.Name, state.Terminal, wipLimit, position, state.ID, projectID)
+			if err != nil {
+				return fmt.Errorf("failed to update workflow state: %w", err)
+			}
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return fmt.Errorf("workflow state %d does not belong to project %d", state.ID, projectID)
+			}
+		}
+
+		if len(transitions) > 0 {
+			from := make([]int64, len(transitions))
+			to := make([]int64, len(transitions))
+			for i, transition := range transitions {
+				from[i] = int64(states[transition[0]].ID)
+				to[i] = int64(states[transition[1]].ID)
+			}
+
+			insertTransitions := `
+				INSERT INTO workflow_transitions (from_state_id, to_state_id)
+				SELECT * FROM UNNEST($1::INTEGER[], $2::INTEGER[])
+			`
+			if _, err := r.conn(ctx).ExecContext(ctx, insertTransitions, pq.Array(from), pq.Array(to)); err != nil {
+				return fmt.Errorf("failed to create workflow transitions: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+	"github.com/swusjask/todo-api/internal/storage"
+)
+
+var (
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrAttachmentTooLarge   = errors.New("attachment too large")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// maxFilenameLength caps stored filenames, in characters
+const maxFilenameLength = 255
+
+// AttachmentService contains business logic for files attached to todos
+type AttachmentService struct {
+	repo         *repository.AttachmentRepository
+	todos        *TodoService
+	blobs        storage.BlobStore
+	maxSize      int64
+	allowedTypes []string
+}
+
+// NewAttachmentService creates a new attachment service
+// Access to a todo's attachments follows access to the todo itself, as decided by todos
+func NewAttachmentService(repo *repository.AttachmentRepository, todos *TodoService, blobs storage.BlobStore, maxSize int64, allowedTypes []string) *AttachmentService {
+	return &AttachmentService{
+		repo:         repo,
+		todos:        todos,
+		blobs:        blobs,
+		maxSize:      maxSize,
+		allowedTypes: allowedTypes,
+	}
+}
+
+// MaxSize returns the largest upload accepted, in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// List retrieves the attachments of a todo the current user can see
+func (s *AttachmentService) List(ctx context.Context, todoID int) ([]*models.Attachment, error) {
+	if _, err := s.todos.GetByID(ctx, todoID, false); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByTodo(ctx, todoID)
+}
+
+// Upload stores a file read from r and attaches it to a todo the current user can edit
+// The content type is sniffed from the content rather than trusted from the client
+func (s *AttachmentService) Upload(ctx context.Context, todoID int, filename string, r io.Reader) (*models.Attachment, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.todos.Authorize(ctx, todoID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	name, err := sanitizeFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	content := bufio.NewReaderSize(r, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(head) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidInput)
+	}
+
+	contentType := http.DetectContentType(head)
+	if !s.allowed(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+
+	key, err := newStorageKey(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one byte past the limit so oversized uploads can be told apart from ones exactly at it
+	size, err := s.blobs.Put(ctx, key, io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		s.discardBlob(key)
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if size > s.maxSize {
+		s.discardBlob(key)
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrAttachmentTooLarge, s.maxSize)
+	}
+
+	attachment := &models.Attachment{
+		TodoID:      todoID,
+		Filename:    name,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+		UploadedBy:  &user.ID,
+	}
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.discardBlob(key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns an attachment of a todo the current user can see together with its content
+// The caller must close the reader
+func (s *AttachmentService) Open(ctx context.Context, todoID, id int) (*models.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := s.get(ctx, todoID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobs.Open(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+
+	return attachment, content, nil
+}
+
+// Delete removes an attachment from a todo the current user can edit, along with its content
+func (s *AttachmentService) Delete(ctx context.Context, todoID, id int) error {
+	attachment, err := s.get(ctx, todoID, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.todos.Authorize(ctx, todoID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, attachment.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// The metadata is gone, so a blob left behind here is only wasted space
+	s.discardBlob(attachment.StorageKey)
+	return nil
+}
+
+// get loads an attachment of a todo the current user can see
+func (s *AttachmentService) get(ctx context.Context, todoID, id int) (*models.Attachment, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	if _, err := s.todos.GetByID(ctx, todoID, false); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.GetByID(ctx, todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// allowed reports whether a content type matches one of the configured types
+func (s *AttachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range s.allowedTypes {
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// discardBlob deletes a blob that no attachment refers to, logging failures
+func (s *AttachmentService) discardBlob(key string) {
+	if err := s.blobs.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to delete attachment blob %s: %v", key, err)
+	}
+}
+
+// newStorageKey generates a random blob key grouped under the todo
+func newStorageKey(todoID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate storage key: %w", err)
+	}
+	return fmt.Sprintf("todos/%d/%s", todoID, hex.EncodeToString(random)), nil
+}
+
+// sanitizeFilename strips any directories from a client-supplied filename and caps its length
+func sanitizeFilename(filename string) (string, error) {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if name == "" || name == "." || name == ".." || name == "/" || !utf8.ValidString(name) {
+		return "", fmt.Errorf("%w: invalid filename", ErrInvalidInput)
+	}
+
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[:maxFilenameLength])
+	}
+
+	return name, nil
+}
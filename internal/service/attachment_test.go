@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"plain name", "report.pdf", "report.pdf"},
+		{"surrounding spaces", "  report.pdf  ", "report.pdf"},
+		{"relative path", "docs/report.pdf", "report.pdf"},
+		{"parent directories", "../../etc/passwd", "passwd"},
+		{"absolute path", "/var/tmp/report.pdf", "report.pdf"},
+		{"backslashes", "C:\\Users\\me\\report.pdf", "report.pdf"},
+		{"backslash traversal", "..\\..\\report.pdf", "report.pdf"},
+		{"trailing slash", "docs/", "docs"},
+		{"multibyte characters", "übersicht.txt", "übersicht.txt"},
+		{"at the length cap", strings.Repeat("a", maxFilenameLength), strings.Repeat("a", maxFilenameLength)},
+		{"over the length cap", strings.Repeat("a", maxFilenameLength+10), strings.Repeat("a", maxFilenameLength)},
+		{"cap counts characters", strings.Repeat("é", maxFilenameLength+1), strings.Repeat("é", maxFilenameLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeFilename(tt.filename)
+			if err != nil {
+				t.Fatalf("sanitizeFilename(%q) returned error: %v", tt.filename, err)
+			}
+			if got != tt.want {
+				t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{"empty", ""},
+		{"only spaces", "   "},
+		{"dot", "."},
+		{"parent directory", ".."},
+		{"parent directory with slash", "../"},
+		{"parent directory with backslash", "..\\"},
+		{"root", "/"},
+		{"backslash root", "\\"},
+		{"invalid UTF-8", "report\xff.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := sanitizeFilename(tt.filename); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("sanitizeFilename(%q) = %q, %v, want ErrInvalidInput", tt.filename, got, err)
+			}
+		})
+	}
+}
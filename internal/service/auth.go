@@ -46,6 +46,12 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
 	req.Username = strings.ToLower(strings.TrimSpace(req.Username))
 
+	// Validate timezone, defaulting to UTC
+	timezone, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if email already exists
 	emailExists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
@@ -79,6 +85,7 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 		LastName:     strings.TrimSpace(req.LastName),
 		IsActive:     true,
 		IsAdmin:      false,
+		Timezone:     timezone,
 	}
 
 	// Save to database
@@ -234,6 +241,35 @@ func (s *AuthService) GetCurrentUser(ctx context.Context, userID int) (*models.U
 	return user.ToResponse(), nil
 }
 
+// UpdateTimezone changes the timezone used for the user's date-relative filters
+// Access tokens carry the timezone, so the change applies once the client refreshes its token
+func (s *AuthService) UpdateTimezone(ctx context.Context, userID int, req *models.UpdateTimezoneRequest) (*models.UserResponse, error) {
+	timezone, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateTimezone(ctx, userID, timezone); err != nil {
+		return nil, err
+	}
+
+	return s.GetCurrentUser(ctx, userID)
+}
+
+// normalizeTimezone validates an IANA timezone name, treating empty as UTC
+func normalizeTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return "UTC", nil
+	}
+
+	if _, err := models.LoadTimezone(timezone); err != nil {
+		return "", fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, timezone)
+	}
+
+	return timezone, nil
+}
+
 // CleanupExpiredTokens removes expired refresh tokens (can be run periodically)
 func (s *AuthService) CleanupExpiredTokens(ctx context.Context) error {
 	return s.userRepo.DeleteExpiredRefreshTokens(ctx)
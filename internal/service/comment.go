@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
+// CommentService contains business logic for comments on todos
+type CommentService struct {
+	repo  *repository.CommentRepository
+	todos *TodoService
+}
+
+// NewCommentService creates a new comment service
+// Access to a todo's comments follows access to the todo itself, as decided by todos
+func NewCommentService(repo *repository.CommentRepository, todos *TodoService) *CommentService {
+	return &CommentService{repo: repo, todos: todos}
+}
+
+// List retrieves a page of the comments on a todo the current user can see, oldest first
+func (s *CommentService) List(ctx context.Context, todoID, page, pageSize int) ([]*models.Comment, int, error) {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	if _, err := s.todos.GetByID(ctx, todoID, false); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.ListByTodo(ctx, todoID, offset, pageSize)
+}
+
+// Create posts a comment by the current user on a todo they can comment on
+func (s *CommentService) Create(ctx context.Context, todoID int, req *models.CreateCommentRequest) (*models.Comment, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := normalizeCommentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.todos.Authorize(ctx, todoID, models.RoleCommenter); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, todoID, user.ID, body)
+}
+
+// Update edits a comment; only its author can do so
+func (s *CommentService) Update(ctx context.Context, id int, req *models.UpdateCommentRequest) (*models.Comment, error) {
+	body, err := normalizeCommentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.authorComment(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if body == comment.Body {
+		return comment, nil
+	}
+
+	updated, err := s.repo.Update(ctx, id, body)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrCommentNotFound
+	}
+
+	return updated, nil
+}
+
+// Delete removes a comment; only its author or an admin can do so
+func (s *CommentService) Delete(ctx context.Context, id int) error {
+	if _, err := s.authorComment(ctx, id, true); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCommentNotFound
+	}
+	return err
+}
+
+// authorComment loads a comment the current user may change: their own, or any when
+// allowAdmin is set and they are an admin
+func (s *CommentService) authorComment(ctx context.Context, id int, allowAdmin bool) (*models.Comment, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	comment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, ErrCommentNotFound
+	}
+
+	forbidden := fmt.Errorf("%w: only the author can change a comment", ErrForbidden)
+	if err := s.todos.authorizeAuthor(ctx, comment.TodoID, comment.AuthorID, allowAdmin, ErrCommentNotFound, forbidden); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// normalizeCommentBody trims a comment body and rejects blank ones
+func normalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: comment must not be empty", ErrInvalidInput)
+	}
+	return body, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// ProjectService contains business logic for the current user's projects
+type ProjectService struct {
+	repo *repository.ProjectRepository
+}
+
+// NewProjectService creates a new project service
+func NewProjectService(repo *repository.ProjectRepository) *ProjectService {
+	return &ProjectService{repo: repo}
+}
+
+// Create adds a project for the current user, optionally nested inside one of their projects
+func (s *ProjectService) Create(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: project name must not be empty", ErrInvalidInput)
+	}
+
+	if req.ParentID != nil {
+		if err := s.checkParent(ctx, user.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	project := &models.Project{
+		UserID:   user.ID,
+		ParentID: req.ParentID,
+		Name:     name,
+		Color:    req.Color,
+	}
+	if err := s.repo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// GetByID retrieves one of the current user's projects
+func (s *ProjectService) GetByID(ctx context.Context, id int) (*models.Project, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.repo.GetByID(ctx, id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// List retrieves the current user's projects; archived ones only when includeArchived is set
+func (s *ProjectService) List(ctx context.Context, includeArchived bool) ([]*models.Project, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByUser(ctx, user.ID, includeArchived)
+}
+
+// Update modifies one of the current user's projects
+// Moving a project below itself or one of its own subprojects is rejected
+func (s *ProjectService) Update(ctx context.Context, id int, req *models.UpdateProjectRequest) (*models.Project, error) {
+	if req.Name == nil && req.Color == nil && req.Archived == nil && req.ParentID == nil {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: project name must not be empty", ErrInvalidInput)
+		}
+		req.Name = &name
+	}
+
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.checkParent(ctx, user.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+
+		cycle, err := s.repo.IsDescendant(ctx, id, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, fmt.Errorf("%w: a project cannot be moved inside itself or one of its subprojects", ErrInvalidInput)
+		}
+	}
+
+	project, err := s.repo.Update(ctx, id, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// Delete removes one of the current user's projects
+// mode decides whether its contents are deleted too or kept; it defaults to keeping them
+func (s *ProjectService) Delete(ctx context.Context, id int, mode models.ProjectDeleteMode) error {
+	if mode == "" {
+		mode = models.ProjectDeleteMove
+	}
+	if mode != models.ProjectDeleteMove && mode != models.ProjectDeleteCascade {
+		return fmt.Errorf("%w: mode must be move or cascade", ErrInvalidInput)
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, id, user.ID, mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkParent verifies that a project can be nested inside parentID
+func (s *ProjectService) checkParent(ctx context.Context, userID, parentID int) error {
+	parent, err := s.repo.GetByID(ctx, parentID, userID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return fmt.Errorf("%w: parent project %d not found", ErrInvalidInput, parentID)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/auth"
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+var (
+	ErrShareNotFound = errors.New("share not found")
+)
+
+// ShareService contains business logic for sharing todos and projects with other users
+// and through share links
+type ShareService struct {
+	repo        *repository.ShareRepository
+	linkRepo    *repository.ShareLinkRepository
+	todoRepo    *repository.TodoRepository
+	projectRepo *repository.ProjectRepository
+	userRepo    *repository.UserRepository
+	todos       *TodoService
+	passwords   *auth.PasswordManager
+}
+
+// NewShareService creates a new share service
+// Who may share a todo follows the roles decided by todos; passwords hashes share link passwords
+func NewShareService(repo *repository.ShareRepository, linkRepo *repository.ShareLinkRepository, todoRepo *repository.TodoRepository, projectRepo *repository.ProjectRepository, userRepo *repository.UserRepository, todos *TodoService, passwords *auth.PasswordManager) *ShareService {
+	return &ShareService{
+		repo:        repo,
+		linkRepo:    linkRepo,
+		todoRepo:    todoRepo,
+		projectRepo: projectRepo,
+		userRepo:    userRepo,
+		todos:       todos,
+		passwords:   passwords,
+	}
+}
+
+// Share gives a user a role on a todo or project the current user owns; sharing
+// again with the same user changes their role
+func (s *ShareService) Share(ctx context.Context, kind models.ShareKind, targetID int, req *models.ShareRequest) (*models.Share, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Role.Grantable() {
+		return nil, fmt.Errorf("%w: role must be one of viewer, commenter, editor", ErrInvalidInput)
+	}
+
+	if err := s.authorize(ctx, kind, targetID, true); err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.invitee(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == user.ID {
+		return nil, fmt.Errorf("%w: you cannot share with yourself", ErrInvalidInput)
+	}
+
+	return s.repo.Upsert(ctx, kind, targetID, invitee.ID, req.Role, &user.ID)
+}
+
+// List retrieves who a todo or project the current user has access to is shared with
+func (s *ShareService) List(ctx context.Context, kind models.ShareKind, targetID int) ([]*models.Share, error) {
+	if err := s.authorize(ctx, kind, targetID, false); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx, kind, targetID)
+}
+
+// Revoke removes a user's share of a todo or project; owners can revoke anyone's share,
+// and collaborators can give up their own
+func (s *ShareService) Revoke(ctx context.Context, kind models.ShareKind, targetID, userID int) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(ctx, kind, targetID, userID != user.ID); err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, kind, targetID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareNotFound
+	}
+	return err
+}
+
+// SharedWithMe lists the todos and projects other users have shared with the current user
+func (s *ShareService) SharedWithMe(ctx context.Context) (*models.SharedWithMe, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.todoRepo.ListSharedWith(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectRepo.ListSharedWith(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SharedWithMe{Todos: todos, Projects: projects}, nil
+}
+
+// authorize checks that the current user has access to a todo or project,
+// and owns it when manage is set
+func (s *ShareService) authorize(ctx context.Context, kind models.ShareKind, targetID int, manage bool) error {
+	if targetID <= 0 {
+		return fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	role := models.RoleViewer
+	if manage {
+		role = models.RoleOwner
+	}
+
+	switch kind {
+	case models.ShareKindTodo:
+		_, err := s.todos.Authorize(ctx, targetID, role)
+		return err
+	case models.ShareKindProject:
+		_, err := s.todos.AuthorizeProject(ctx, targetID, role)
+		return err
+	default:
+		return fmt.Errorf("%w: unknown share kind %q", ErrInvalidInput, kind)
+	}
+}
+
+// invitee looks up the active user a share request names by ID or email
+func (s *ShareService) invitee(ctx context.Context, req *models.ShareRequest) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if (req.UserID == nil) == (email == "") {
+		return nil, fmt.Errorf("%w: provide either user_id or email", ErrInvalidInput)
+	}
+
+	var (
+		user *models.User
+		err  error
+	)
+	if req.UserID != nil {
+		user, err = s.userRepo.GetByID(ctx, *req.UserID)
+	} else {
+		user, err = s.userRepo.GetByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, fmt.Errorf("%w: user not found", ErrInvalidInput)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/auth"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+var (
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkExpired  = errors.New("share link has expired")
+	ErrShareLinkPassword = errors.New("share link password is missing or incorrect")
+)
+
+// CreateLink creates a share link for a todo or project the current user owns
+// The returned link carries its token; it can't be retrieved again later
+func (s *ShareService) CreateLink(ctx context.Context, kind models.ShareKind, targetID int, req *models.ShareLinkRequest) (*models.ShareLink, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidInput)
+	}
+
+	if err := s.authorize(ctx, kind, targetID, true); err != nil {
+		return nil, err
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		if passwordHash, err = s.passwords.HashPassword(req.Password); err != nil {
+			return nil, fmt.Errorf("failed to hash share link password: %w", err)
+		}
+	}
+
+	token, err := newLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.Create(ctx, kind, targetID, hashLinkToken(token), passwordHash, req.ExpiresAt, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	link.Token = token
+	return link, nil
+}
+
+// ListLinks retrieves the share links of a todo or project the current user owns
+func (s *ShareService) ListLinks(ctx context.Context, kind models.ShareKind, targetID int) ([]*models.ShareLink, error) {
+	if err := s.authorize(ctx, kind, targetID, true); err != nil {
+		return nil, err
+	}
+
+	return s.linkRepo.List(ctx, kind, targetID)
+}
+
+// RevokeLink deletes a share link of a todo or project the current user owns
+func (s *ShareService) RevokeLink(ctx context.Context, kind models.ShareKind, targetID, linkID int) error {
+	if err := s.authorize(ctx, kind, targetID, true); err != nil {
+		return err
+	}
+
+	err := s.linkRepo.Delete(ctx, kind, targetID, linkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareLinkNotFound
+	}
+	return err
+}
+
+// OpenLink resolves a share link token into the read-only view it grants and counts the view
+// Project links show one page of the project's top-level todos
+func (s *ShareService) OpenLink(ctx context.Context, token, password string, page, pageSize int) (*models.PublicShare, error) {
+	if token == "" {
+		return nil, ErrShareLinkNotFound
+	}
+
+	link, passwordHash, err := s.linkRepo.GetByTokenHash(ctx, hashLinkToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	if link.Expired(time.Now()) {
+		return nil, ErrShareLinkExpired
+	}
+	if link.HasPassword {
+		if err := s.passwords.CheckPassword(password, passwordHash); err != nil {
+			if errors.Is(err, auth.ErrInvalidPassword) {
+				return nil, ErrShareLinkPassword
+			}
+			return nil, fmt.Errorf("failed to check share link password: %w", err)
+		}
+	}
+
+	var view *models.PublicShare
+	if link.TodoID != nil {
+		view, err = s.todoView(ctx, *link.TodoID)
+	} else {
+		view, err = s.projectView(ctx, *link.ProjectID, link.CreatedBy, page, pageSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.RecordView(ctx, link.ID); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// todoView shows a todo with its subtask tree; trashed todos are not shown
+func (s *ShareService) todoView(ctx context.Context, todoID int) (*models.PublicShare, error) {
+	todo, err := s.todoRepo.GetByID(ctx, todoID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	if err := s.todoRepo.AttachSubtasks(ctx, []*models.Todo{todo}, maxSubtaskDepth); err != nil {
+		return nil, err
+	}
+
+	return &models.PublicShare{Kind: models.ShareKindTodo, Todo: todo.Public()}, nil
+}
+
+// projectView shows a project with a page of its top-level todos
+// Only owners create project links, so the link's creator owns the project
+func (s *ShareService) projectView(ctx context.Context, projectID, ownerID, page, pageSize int) (*models.PublicShare, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	page, pageSize = NormalizePagination(page, pageSize)
+	filter := &models.TodoFilter{ProjectID: &projectID, TopLevel: true}
+	todos, totalCount, err := s.todoRepo.List(ctx, filter, nil, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	public := make([]*models.PublicTodo, 0, len(todos))
+	for _, todo := range todos {
+		public = append(public, todo.Public())
+	}
+
+	return &models.PublicShare{
+		Kind:       models.ShareKindProject,
+		Project:    &models.PublicProject{Name: project.Name, Color: project.Color},
+		Todos:      public,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// newLinkToken generates an unguessable URL-safe share link token
+func newLinkToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate share link token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashLinkToken returns the hex SHA-256 of a token, the form tokens are stored and looked up in
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// maxTagsPerTodo limits how many tags a single todo can carry
+const maxTagsPerTodo = 20
+
+// TagService contains business logic for the current user's tags
+type TagService struct {
+	repo *repository.TagRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(repo *repository.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+// Create adds a tag for the current user
+func (s *TagService) Create(ctx context.Context, req *models.CreateTagRequest) (*models.Tag, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.ExistsByName(ctx, user.ID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTagExists
+	}
+
+	tag := &models.Tag{
+		UserID: user.ID,
+		Name:   name,
+		Color:  req.Color,
+	}
+	if err := s.repo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// GetByID retrieves one of the current user's tags
+func (s *TagService) GetByID(ctx context.Context, id int) (*models.Tag, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.repo.GetByID(ctx, id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+
+	return tag, nil
+}
+
+// List retrieves all of the current user's tags
+func (s *TagService) List(ctx context.Context) ([]*models.Tag, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByUser(ctx, user.ID)
+}
+
+// Update renames or recolors one of the current user's tags
+func (s *TagService) Update(ctx context.Context, id int, req *models.UpdateTagRequest) (*models.Tag, error) {
+	if req.Name == nil && req.Color == nil {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name, err := normalizeTagName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		req.Name = &name
+
+		exists, err := s.repo.ExistsByName(ctx, user.ID, name, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrTagExists
+		}
+	}
+
+	tag, err := s.repo.Update(ctx, id, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+
+	return tag, nil
+}
+
+// Delete removes one of the current user's tags from every todo and deletes it
+func (s *TagService) Delete(ctx context.Context, id int) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, id, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// requireUser returns the authenticated user, for operations that only make sense for one
+func requireUser(ctx context.Context) (*models.UserContext, error) {
+	user := models.GetUserFromContext(ctx)
+	if user == nil {
+		return nil, fmt.Errorf("%w: authentication required", ErrForbidden)
+	}
+	return user, nil
+}
+
+// normalizeTagName trims a tag name and rejects names that can't round-trip through filters
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: tag names must not be empty", ErrInvalidInput)
+	}
+	if len([]rune(name)) > 50 {
+		return "", fmt.Errorf("%w: tag names must be at most 50 characters", ErrInvalidInput)
+	}
+	// Commas separate tags in the tag and tag_any query parameters
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("%w: tag names must not contain commas", ErrInvalidInput)
+	}
+	return name, nil
+}
+
+// normalizeTagNames normalizes a list of tag names and drops case-insensitive duplicates
+func normalizeTagNames(names []string) ([]string, error) {
+	if names == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+
+	if len(normalized) > maxTagsPerTodo {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidInput, maxTagsPerTodo)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+var (
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+	ErrTimerNotRunning   = errors.New("no timer running")
+)
+
+// TimeEntryService contains business logic for time tracking on todos
+type TimeEntryService struct {
+	repo  *repository.TimeEntryRepository
+	todos *TodoService
+
+	// zones remembers which timezones the database can count days in; only names Go knows
+	// are looked up, so it stays small, and the database's zones don't change while it runs
+	zones sync.Map
+}
+
+// NewTimeEntryService creates a new time entry service
+// Tracking time on a todo takes editor access to it, as decided by todos
+func NewTimeEntryService(repo *repository.TimeEntryRepository, todos *TodoService) *TimeEntryService {
+	return &TimeEntryService{repo: repo, todos: todos}
+}
+
+// StartTimer starts a timer for the current user on a todo they can edit
+// A timer they already have running, on any todo, is stopped first
+func (s *TimeEntryService) StartTimer(ctx context.Context, todoID int) (*models.TimeEntry, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.todos.Authorize(ctx, todoID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Start(ctx, todoID, user.ID, time.Now())
+}
+
+// StopTimer stops the timer the current user has running on a todo
+func (s *TimeEntryService) StopTimer(ctx context.Context, todoID int) (*models.TimeEntry, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.Stop(ctx, todoID, user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		if _, err := s.todos.GetByID(ctx, todoID, false); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w on todo %d", ErrTimerNotRunning, todoID)
+	}
+
+	return entry, nil
+}
+
+// Create logs a finished time entry by the current user on a todo they can edit
+func (s *TimeEntryService) Create(ctx context.Context, todoID int, req *models.CreateTimeEntryRequest) (*models.TimeEntry, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeRange(req.StartedAt, &req.EndedAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.todos.Authorize(ctx, todoID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, todoID, user.ID, req.StartedAt, req.EndedAt, strings.TrimSpace(req.Note))
+}
+
+// ListByTodo retrieves a page of everyone's time entries on a todo the current user can see
+func (s *TimeEntryService) ListByTodo(ctx context.Context, todoID, page, pageSize int) ([]*models.TimeEntry, int, error) {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	if _, err := s.todos.GetByID(ctx, todoID, false); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, &models.TimeEntryFilter{TodoID: &todoID}, offset, pageSize)
+}
+
+// List retrieves a page of the current user's time entries matching the filter
+// Admins can ask for every user's entries with allUsers
+func (s *TimeEntryService) List(ctx context.Context, filter *models.TimeEntryFilter, page, pageSize int, allUsers bool) ([]*models.TimeEntry, int, error) {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	if err := s.scope(ctx, filter, allUsers); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, filter, offset, pageSize)
+}
+
+// Totals sums the current user's time entries matching the filter by todo, project or day
+// Days are counted in timezone, falling back to the user's own; admins can total every
+// user's entries with allUsers
+func (s *TimeEntryService) Totals(ctx context.Context, filter *models.TimeEntryFilter, groupBy models.TimeGrouping, timezone string, allUsers bool) (*models.TimeTotals, error) {
+	if !groupBy.IsValid() {
+		return nil, fmt.Errorf("%w: group_by must be todo, project or day", ErrInvalidInput)
+	}
+
+	loc := models.GetUserFromContext(ctx).Location()
+	if timezone != "" {
+		var err error
+		if loc, err = models.LoadTimezone(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, timezone)
+		}
+	}
+
+	if groupBy == models.GroupByDay {
+		known, err := s.knowsTimezone(ctx, loc.String())
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: days can't be counted in the timezone %q", ErrInvalidInput, loc.String())
+		}
+	}
+
+	if err := s.scope(ctx, filter, allUsers); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.Totals(ctx, filter, groupBy, loc.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TimeTotals{GroupBy: groupBy, Timezone: loc.String(), Totals: totals}
+	for _, total := range totals {
+		result.Seconds += total.Seconds
+	}
+
+	return result, nil
+}
+
+// Update changes the times and note of one of the current user's time entries
+// A running timer may stay running; a stopped entry can't be restarted
+func (s *TimeEntryService) Update(ctx context.Context, id int, req *models.UpdateTimeEntryRequest) (*models.TimeEntry, error) {
+	entry, err := s.ownEntry(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EndedAt == nil && !entry.Running() {
+		return nil, fmt.Errorf("%w: ended_at is required; start a timer to track time again", ErrInvalidInput)
+	}
+	if err := validateTimeRange(req.StartedAt, req.EndedAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(ctx, id, req.StartedAt, req.EndedAt, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrTimeEntryNotFound
+	}
+
+	return updated, nil
+}
+
+// Delete removes a time entry; only the user who tracked it or an admin can do so
+func (s *TimeEntryService) Delete(ctx context.Context, id int) error {
+	if _, err := s.ownEntry(ctx, id, true); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTimeEntryNotFound
+	}
+	return err
+}
+
+// knowsTimezone reports whether the database can count days in a timezone, asking it only
+// the first time, since its catalog of timezones is slow to search
+func (s *TimeEntryService) knowsTimezone(ctx context.Context, timezone string) (bool, error) {
+	if known, ok := s.zones.Load(timezone); ok {
+		return known.(bool), nil
+	}
+
+	known, err := s.repo.KnowsTimezone(ctx, timezone)
+	if err != nil {
+		return false, err
+	}
+	s.zones.Store(timezone, known)
+
+	return known, nil
+}
+
+// scope validates a time entry filter and restricts it to the current user's entries, unless
+// an admin, or the owner of the project the filter is for, explicitly asked for every user's
+func (s *TimeEntryService) scope(ctx context.Context, filter *models.TimeEntryFilter, allUsers bool) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: from must be earlier than to", ErrInvalidInput)
+	}
+	if filter.ProjectID != nil && filter.Inbox {
+		return fmt.Errorf("%w: project_id cannot be both a project and inbox", ErrInvalidInput)
+	}
+
+	if allUsers {
+		// Project owners bill for the time everyone spent on their project
+		if !user.IsAdmin {
+			if filter.ProjectID == nil {
+				return fmt.Errorf("%w: only admins can view all users' time entries outside a project they own", ErrForbidden)
+			}
+			if _, err := s.todos.AuthorizeProject(ctx, *filter.ProjectID, models.RoleOwner); err != nil {
+				return err
+			}
+		}
+		filter.UserID = nil
+		return nil
+	}
+
+	filter.UserID = &user.ID
+	return nil
+}
+
+// ownEntry loads a time entry for the user who tracked it to change; with allowAdmin,
+// admins can get at anyone's too, to delete time booked by mistake
+func (s *TimeEntryService) ownEntry(ctx context.Context, id int, allowAdmin bool) (*models.TimeEntry, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	entry, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrTimeEntryNotFound
+	}
+
+	forbidden := fmt.Errorf("%w: only the user who tracked a time entry can change it", ErrForbidden)
+	if err := s.todos.authorizeAuthor(ctx, entry.TodoID, &entry.UserID, allowAdmin, ErrTimeEntryNotFound, forbidden); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// validateTimeRange checks that a time entry starts before it ends and doesn't reach into the future
+// A nil end stands for a timer that is still running
+func validateTimeRange(startedAt time.Time, endedAt *time.Time, now time.Time) error {
+	if startedAt.After(now) {
+		return fmt.Errorf("%w: started_at must not be in the future", ErrInvalidInput)
+	}
+	if endedAt == nil {
+		return nil
+	}
+	if !endedAt.After(startedAt) {
+		return fmt.Errorf("%w: ended_at must be later than started_at", ErrInvalidInput)
+	}
+	if endedAt.After(now) {
+		return fmt.Errorf("%w: ended_at must not be in the future", ErrInvalidInput)
+	}
+	return nil
+}
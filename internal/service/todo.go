@@ -1,112 +1,681 @@
-package service
-
-import (
-	"context"
-	"database/sql"
-	"errors"
-	"fmt"
-
-	"github.com/swusjask/todo-api/internal/models"
-	"github.com/swusjask/todo-api/internal/repository"
-)
-
-// Common errors that the service layer might return
-var (
-	ErrTodoNotFound = errors.New("todo not found")
-	ErrInvalidInput = errors.New("invalid input")
-)
-
-// TodoService contains business logic for todo operations
-// This layer is where you'd add things like validation, authorization, or complex business rules
-type TodoService struct {
-	repo *repository.TodoRepository
-}
-
-func NewTodoService(repo *repository.TodoRepository) *TodoService {
-	return &TodoService{repo: repo}
-}
-
-// Create validates and creates a new todo
-func (s *TodoService) Create(ctx context.Context, req *models.CreateTodoRequest) (*models.Todo, error) {
-	// Business logic validation beyond what Gin binding provides
-	if len(req.Title) < 3 {
-		return nil, fmt.Errorf("%w: title must be at least 3 characters", ErrInvalidInput)
-	}
-
-	// In a real app, you might check user permissions here
-	// or enforce business rules like "max 100 todos per user"
-
-	return s.repo.Create(ctx, req)
-}
-
-// GetByID retrieves a single todo
-func (s *TodoService) GetByID(ctx context.Context, id int) (*models.Todo, error) {
-	if id <= 0 {
-		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
-	}
-
-	todo, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	if todo == nil {
-		return nil, ErrTodoNotFound
-	}
-
-	return todo, nil
-}
-
-// List retrieves todos with pagination
-func (s *TodoService) List(ctx context.Context, page, pageSize int) ([]*models.Todo, int, error) {
-	// Validate and set defaults for pagination
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20 // Default page size
-	}
-
-	offset := (page - 1) * pageSize
-	return s.repo.List(ctx, offset, pageSize)
-}
-
-// Update modifies an existing todo
-func (s *TodoService) Update(ctx context.Context, id int, req *models.UpdateTodoRequest) (*models.Todo, error) {
-	if id <= 0 {
-		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
-	}
-
-	// Validate that at least one field is being updated
-	if req.Title == nil && req.Description == nil && req.Completed == nil {
-		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
-	}
-
-	todo, err := s.repo.Update(ctx, id, req)
-	if err != nil {
-		return nil, err
-	}
-	if todo == nil {
-		return nil, ErrTodoNotFound
-	}
-
-	return todo, nil
-}
-
-// Delete removes a todo
-func (s *TodoService) Delete(ctx context.Context, id int) error {
-	if id <= 0 {
-		return fmt.Errorf("%w: invalid ID", ErrInvalidInput)
-	}
-
-	// In a real app, you might check permissions or archive instead of delete
-
-	err := s.repo.Delete(ctx, id)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrTodoNotFound
-		}
-		return err
-	}
-
-	return nil
-}
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+	"github.com/swusjask/todo-api/internal/storage"
+)
+
+// Common errors that the service layer might return
+var (
+	ErrTodoNotFound = errors.New("todo not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrForbidden    = errors.New("forbidden")
+	ErrTodoBlocked  = errors.New("todo is blocked")
+
+	// ErrVersionMismatch means a conditional write found the todo at a version other than expected
+	ErrVersionMismatch = errors.New("todo has been modified")
+)
+
+// maxSubtaskDepth caps how many levels of subtasks can be nested below a top-level todo
+const maxSubtaskDepth = 5
+
+// TodoService contains business logic for todo operations
+// This layer is where you'd add things like validation, authorization, or complex business rules
+type TodoService struct {
+	repo         *repository.TodoRepository
+	projectRepo  *repository.ProjectRepository
+	userRepo     *repository.UserRepository
+	shareRepo    *repository.ShareRepository
+	workflowRepo *repository.WorkflowRepository
+	blobs        storage.BlobStore
+}
+
+func NewTodoService(repo *repository.TodoRepository, projectRepo *repository.ProjectRepository, userRepo *repository.UserRepository, shareRepo *repository.ShareRepository, workflowRepo *repository.WorkflowRepository, blobs storage.BlobStore) *TodoService {
+	return &TodoService{repo: repo, projectRepo: projectRepo, userRepo: userRepo, shareRepo: shareRepo, workflowRepo: workflowRepo, blobs: blobs}
+}
+
+// Create validates and creates a new todo
+func (s *TodoService) Create(ctx context.Context, req *models.CreateTodoRequest) (*models.Todo, error) {
+	// Business logic validation beyond what Gin binding provides
+	if utf8.RuneCountInString(req.Title) < 3 {
+		return nil, fmt.Errorf("%w: title must be at least 3 characters", ErrInvalidInput)
+	}
+
+	if err := validateSchedule(req.StartAt, req.DueAt); err != nil {
+		return nil, err
+	}
+
+	if req.Priority == "" {
+		req.Priority = models.PriorityNone
+	}
+	if err := validatePriority(req.Priority); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTagNames(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	req.Tags = tags
+
+	if req.ProjectID != nil {
+		if err := s.checkProject(ctx, *req.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ParentID != nil {
+		if err := s.checkParent(ctx, 0, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.AssigneeID != nil {
+		if err := s.checkAssignee(ctx, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkCreateStatus(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// In a real app, you might check user permissions here
+	// or enforce business rules like "max 100 todos per user"
+
+	var recurrence *models.Recurrence
+	var ownerID *int
+	if req.Recurrence != nil {
+		if recurrence, err = resolveRecurrence(ctx, req.Recurrence, req.DueAt); err != nil {
+			return nil, err
+		}
+		if ownerID, err = s.ownerScope(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+
+	// The todo counts against the limit of the state it starts in, named or not
+	var todo *models.Todo
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if todo, err = s.repo.Create(ctx, req); err != nil {
+			return err
+		}
+		if err := s.checkWIPLimit(ctx, nil, todo); err != nil {
+			return err
+		}
+		if recurrence == nil {
+			return nil
+		}
+		todo, err = s.repo.SetRecurrence(ctx, todo.ID, ownerID, recurrence, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// GetByID retrieves a single todo owned by the current user
+// Admins can set allUsers to look up any user's todo
+func (s *TodoService) GetByID(ctx context.Context, id int, allUsers bool) (*models.Todo, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	ownerID, err := s.ownerScope(ctx, allUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return todo, nil
+}
+
+// List retrieves the current user's todos matching the filter, sorted and paginated
+// Admins can set allUsers to list every user's todos
+func (s *TodoService) List(ctx context.Context, filter *models.TodoFilter, sort []models.SortField, page, pageSize int, allUsers bool) ([]*models.Todo, int, error) {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	if err := validateSort(sort); err != nil {
+		return nil, 0, err
+	}
+
+	filter, err := s.prepareFilter(ctx, filter, allUsers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, filter, sort, offset, pageSize)
+}
+
+// ListAfter retrieves one page of the current user's todos using keyset pagination
+// over (created_at, id), newest first. It returns the cursor of the next page, or nil
+// on the last page, and the total count only when includeTotal is set, since counting
+// is what makes large listings slow
+func (s *TodoService) ListAfter(ctx context.Context, filter *models.TodoFilter, after *models.TodoCursor, pageSize int, includeTotal, allUsers bool) ([]*models.Todo, *models.TodoCursor, *int, error) {
+	_, pageSize = NormalizePagination(1, pageSize)
+
+	filter, err := s.prepareFilter(ctx, filter, allUsers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Fetch one extra row to find out whether another page exists
+	todos, err := s.repo.ListAfter(ctx, filter, after, pageSize+1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var next *models.TodoCursor
+	if len(todos) > pageSize {
+		todos = todos[:pageSize]
+		last := todos[len(todos)-1]
+		next = &models.TodoCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	var totalCount *int
+	if includeTotal {
+		count, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		totalCount = &count
+	}
+
+	return todos, next, totalCount, nil
+}
+
+// Search runs a full-text search over the current user's todos, best matches first
+// Admins can set allUsers to search every user's todos
+func (s *TodoService) Search(ctx context.Context, query string, filter *models.TodoFilter, page, pageSize int, allUsers bool) ([]*models.TodoSearchResult, int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, fmt.Errorf("%w: search query is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(query) > 500 {
+		return nil, 0, fmt.Errorf("%w: search query must be at most 500 characters", ErrInvalidInput)
+	}
+
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	filter, err := s.prepareFilter(ctx, filter, allUsers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.Search(ctx, query, filter, offset, pageSize)
+}
+
+// ListChildren retrieves the direct subtasks of a todo owned by the current user
+// Admins can set allUsers to look at any user's todo
+func (s *TodoService) ListChildren(ctx context.Context, id int, allUsers bool) ([]*models.Todo, error) {
+	if _, err := s.GetByID(ctx, id, allUsers); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListChildren(ctx, id)
+}
+
+// ExpandSubtasks nests the subtask tree of each todo into its Subtasks, down to maxSubtaskDepth
+func (s *TodoService) ExpandSubtasks(ctx context.Context, todos ...*models.Todo) error {
+	return s.repo.AttachSubtasks(ctx, todos, maxSubtaskDepth)
+}
+
+// NormalizePagination applies the default page (1) and page size (20, max 100)
+func NormalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20 // Default page size
+	}
+	return page, pageSize
+}
+
+// prepareFilter validates a list filter and restricts it to what the caller may see
+func (s *TodoService) prepareFilter(ctx context.Context, filter *models.TodoFilter, allUsers bool) (*models.TodoFilter, error) {
+	if filter == nil {
+		filter = &models.TodoFilter{}
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	for _, priority := range filter.Priorities {
+		if err := validatePriority(priority); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if filter.Tags, err = normalizeTagNames(filter.Tags); err != nil {
+		return nil, err
+	}
+	if filter.TagsAny, err = normalizeTagNames(filter.TagsAny); err != nil {
+		return nil, err
+	}
+	if err := resolveDueFilters(ctx, filter, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := validateTimeRanges(filter); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerScope(ctx, allUsers)
+	if err != nil {
+		return nil, err
+	}
+	filter.OwnerID = ownerID
+
+	if filter.AssignedToMe {
+		user, err := requireUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.AssigneeID = &user.ID
+	}
+
+	// Listing a project that isn't the caller's is a 404, not an empty page
+	// Everyone with access to a project sees all of its todos, whoever created them
+	if filter.ProjectID != nil && ownerID != nil {
+		project, _, err := s.projectRole(ctx, *filter.ProjectID, *ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if project == nil {
+			return nil, ErrProjectNotFound
+		}
+		if !filter.Deleted {
+			filter.OwnerID = nil
+		}
+	}
+
+	return filter, nil
+}
+
+// Update modifies an existing todo the current user can edit
+func (s *TodoService) Update(ctx context.Context, id int, req *models.UpdateTodoRequest) (*models.Todo, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	// Validate that at least one field is being updated
+	if req.Title == nil && req.Description == nil && req.Completed == nil &&
+		req.DueAt == nil && req.StartAt == nil && req.Priority == nil && req.Tags == nil &&
+		req.ProjectID == nil && req.ParentID == nil && req.AssigneeID == nil && req.StatusID == nil {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
+	}
+
+	if req.Priority != nil {
+		if err := validatePriority(*req.Priority); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Tags != nil {
+		tags, err := normalizeTagNames(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		req.Tags = &tags
+	}
+
+	// Viewers and commenters can see the todo but not change it
+	existing, err := s.Authorize(ctx, id, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ProjectID != nil {
+		var projectID *int
+		if *req.ProjectID != 0 {
+			projectID = req.ProjectID
+		}
+		if err := s.checkMove(ctx, existing, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.checkParent(ctx, id, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Workflow states and completion go together, so this may set either
+	if err := s.resolveUpdateStatus(ctx, existing, req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rescheduling := req.DueAt != nil || req.StartAt != nil
+	completing := req.Completed != nil && *req.Completed
+
+	if req.AssigneeID != nil {
+		if err := s.changeAssignee(ctx, existing, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Recurrences are counted from the due date, so it can't simply disappear
+	if req.ClearDueAt && req.DueAt == nil && existing.Recurrence != nil {
+		return nil, fmt.Errorf("%w: stop the recurrence before removing the due date", ErrInvalidInput)
+	}
+
+	// A new start or due date has to be checked against the one that isn't changing
+	if rescheduling {
+		startAt, dueAt := existing.StartAt, existing.DueAt
+		if req.StartAt != nil {
+			startAt = req.StartAt
+		} else if req.ClearStartAt {
+			startAt = nil
+		}
+		if req.DueAt != nil {
+			dueAt = req.DueAt
+		} else if req.ClearDueAt {
+			dueAt = nil
+		}
+		if err := validateSchedule(startAt, dueAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if completing && !req.Force && !existing.Completed {
+		open, err := s.repo.CountOpenBlockers(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, fmt.Errorf("%w: %d blocking todo(s) still open; set force to complete it anyway", ErrTodoBlocked, open)
+		}
+	}
+
+	// Completing an occurrence of a recurring todo creates the next one in the same transaction
+	spawning := completing && !existing.Completed && existing.Recurrence != nil
+
+	var todo *models.Todo
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if todo, err = s.repo.Update(ctx, id, ownerID, req); err != nil || todo == nil {
+			return err
+		}
+		// A todo entering a state, by name, by completion or by changing project, counts against its limit
+		if err := s.checkWIPLimit(ctx, existing.StatusID, todo); err != nil {
+			return err
+		}
+		if !spawning {
+			return nil
+		}
+		return s.spawnNextOccurrence(ctx, todo, ownerID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, s.staleOrMissing(ctx, id, ownerID, req.IfMatch)
+	}
+
+	return todo, nil
+}
+
+// Delete removes a todo the current user owns: one they created, or one filed in their project
+// A non-nil ifMatch only deletes the todo while its version is one of those listed
+func (s *TodoService) Delete(ctx context.Context, id int, ifMatch []int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	// Deleted todos go to the trash and are purged once the retention period has passed
+
+	// Collaborators, even editors, can't delete a todo; only its owners can
+	if _, err := s.Authorize(ctx, id, models.RoleOwner); err != nil {
+		return err
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, id, ownerID, ifMatch)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.staleOrMissing(ctx, id, ownerID, ifMatch)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// staleOrMissing explains why a conditional write matched no todo: ErrVersionMismatch
+// when the todo still exists at another version, ErrTodoNotFound otherwise
+func (s *TodoService) staleOrMissing(ctx context.Context, id int, ownerID *int, ifMatch []int) error {
+	if ifMatch == nil {
+		return ErrTodoNotFound
+	}
+
+	todo, err := s.repo.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if todo == nil {
+		return ErrTodoNotFound
+	}
+
+	return ErrVersionMismatch
+}
+
+// checkParent verifies that the todo with the given id (0 for a new todo) can become a subtask
+// of parentID: the parent must be the current user's, the move must not create a cycle,
+// and the resulting tree must not nest deeper than maxSubtaskDepth
+func (s *TodoService) checkParent(ctx context.Context, id, parentID int) error {
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.repo.GetByID(ctx, parentID, ownerID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return fmt.Errorf("%w: parent todo %d not found", ErrInvalidInput, parentID)
+	}
+
+	height := 0
+	if id != 0 {
+		var cycle bool
+		height, cycle, err = s.repo.Subtree(ctx, id, parentID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: a todo cannot become a subtask of itself or of one of its subtasks", ErrInvalidInput)
+		}
+	}
+
+	depth, err := s.repo.Depth(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if depth+1+height > maxSubtaskDepth {
+		return fmt.Errorf("%w: subtasks cannot be nested more than %d levels deep", ErrInvalidInput, maxSubtaskDepth)
+	}
+
+	return nil
+}
+
+// checkProject verifies that the current user can file todos under projectID:
+// it must be their own project or one shared with them as editor
+func (s *TodoService) checkProject(ctx context.Context, projectID int) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	project, role, err := s.projectRole(ctx, projectID, user.ID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return fmt.Errorf("%w: project %d not found", ErrInvalidInput, projectID)
+	}
+	if !role.Includes(models.RoleEditor) {
+		return fmt.Errorf("%w: this requires editor access to project %d", ErrForbidden, projectID)
+	}
+	if project.Archived {
+		return fmt.Errorf("%w: project %d is archived", ErrInvalidInput, projectID)
+	}
+
+	return nil
+}
+
+// validatePriority rejects priorities other than none, low, medium, high and urgent
+func validatePriority(priority models.Priority) error {
+	if !priority.Valid() {
+		return fmt.Errorf("%w: priority must be one of none, low, medium, high, urgent", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateSchedule rejects a start date that falls after the due date
+func validateSchedule(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		return fmt.Errorf("%w: start_at must not be later than due_at", ErrInvalidInput)
+	}
+	return nil
+}
+
+// resolveDueFilters turns the relative due date filters into concrete due_at bounds
+// "Today" is evaluated in the filter's timezone, falling back to the user's own
+// When several filters apply, the bounds are intersected
+func resolveDueFilters(ctx context.Context, filter *models.TodoFilter, now time.Time) error {
+	loc := models.GetUserFromContext(ctx).Location()
+	if filter.Timezone != "" {
+		var err error
+		if loc, err = models.LoadTimezone(filter.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, filter.Timezone)
+		}
+	}
+
+	if filter.Overdue {
+		if filter.Completed != nil && *filter.Completed {
+			return fmt.Errorf("%w: overdue cannot be combined with completed=true", ErrInvalidInput)
+		}
+		open := false
+		filter.Completed = &open
+		filter.DueBefore = earliest(filter.DueBefore, now)
+	}
+
+	if filter.DueToday {
+		local := now.In(loc)
+		startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		// AddDate rather than 24h so days with a DST change keep their real length
+		filter.DueAfter = latest(filter.DueAfter, startOfDay)
+		filter.DueBefore = earliest(filter.DueBefore, startOfDay.AddDate(0, 0, 1))
+	}
+
+	if filter.DueWithin < 0 {
+		return fmt.Errorf("%w: due_within must be positive", ErrInvalidInput)
+	}
+	if filter.DueWithin > 0 {
+		filter.DueAfter = latest(filter.DueAfter, now)
+		filter.DueBefore = earliest(filter.DueBefore, now.Add(filter.DueWithin))
+	}
+
+	return nil
+}
+
+// earliest returns the earlier of an optional bound and t
+func earliest(bound *time.Time, t time.Time) *time.Time {
+	if bound != nil && bound.Before(t) {
+		return bound
+	}
+	return &t
+}
+
+// latest returns the later of an optional bound and t
+func latest(bound *time.Time, t time.Time) *time.Time {
+	if bound != nil && bound.After(t) {
+		return bound
+	}
+	return &t
+}
+
+// validateTimeRanges rejects filters whose lower bound is after their upper bound
+func validateTimeRanges(filter *models.TodoFilter) error {
+	ranges := []struct {
+		name          string
+		after, before *time.Time
+	}{
+		{"created", filter.CreatedAfter, filter.CreatedBefore},
+		{"updated", filter.UpdatedAfter, filter.UpdatedBefore},
+		{"completed", filter.CompletedAfter, filter.CompletedBefore},
+		{"due", filter.DueAfter, filter.DueBefore},
+	}
+
+	for _, r := range ranges {
+		if r.after != nil && r.before != nil && r.after.After(*r.before) {
+			return fmt.Errorf("%w: %s_after must not be later than %s_before", ErrInvalidInput, r.name, r.name)
+		}
+	}
+
+	return nil
+}
+
+// validateSort rejects unknown or repeated sort fields
+func validateSort(sort []models.SortField) error {
+	seen := make(map[string]bool, len(sort))
+	for _, field := range sort {
+		if !models.TodoSortFields[field.Field] {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidInput, field.Field)
+		}
+		if seen[field.Field] {
+			return fmt.Errorf("%w: %q appears more than once in sort", ErrInvalidInput, field.Field)
+		}
+		seen[field.Field] = true
+	}
+	return nil
+}
+
+// ownerScope returns the created_by value todo queries must be restricted to
+// A nil result means no restriction, for admins who explicitly asked for every user's todos
+// Anonymous callers are rejected; they only see todos through share links
+func (s *TodoService) ownerScope(ctx context.Context, allUsers bool) (*int, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if allUsers {
+		if !user.IsAdmin {
+			return nil, fmt.Errorf("%w: only admins can view all users' todos", ErrForbidden)
+		}
+		return nil, nil
+	}
+
+	return &user.ID, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// checkAssignee verifies that a todo can be assigned to the user with the given ID
+func (s *TodoService) checkAssignee(ctx context.Context, assigneeID int) error {
+	if assigneeID <= 0 {
+		return fmt.Errorf("%w: assignee_id must be a user ID", ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, assigneeID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return fmt.Errorf("%w: user %d not found", ErrInvalidInput, assigneeID)
+	}
+
+	return nil
+}
+
+// checkReassign verifies that the current user may change who a todo is assigned to:
+// only its creator and its current assignee can
+func (s *TodoService) checkReassign(ctx context.Context, todo *models.Todo) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if sameID(todo.CreatedBy, &user.ID) || sameID(todo.AssigneeID, &user.ID) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: only the creator or the assignee can reassign a todo", ErrForbidden)
+}
+
+// changeAssignee validates moving a todo from its current assignee to assigneeID, where 0 unassigns it
+func (s *TodoService) changeAssignee(ctx context.Context, todo *models.Todo, assigneeID int) error {
+	if (assigneeID == 0 && todo.AssigneeID == nil) || sameID(todo.AssigneeID, &assigneeID) {
+		return nil
+	}
+
+	if err := s.checkReassign(ctx, todo); err != nil {
+		return err
+	}
+
+	if assigneeID == 0 {
+		return nil
+	}
+	return s.checkAssignee(ctx, assigneeID)
+}
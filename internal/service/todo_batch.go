@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// maxBatchOperations caps how many operations a single batch may contain
+const maxBatchOperations = 100
+
+// BatchError reports the operation that made an all-or-nothing batch roll back
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Batch runs todo operations of the current user in one transaction
+// By default the first failing operation rolls back the whole batch and is returned as a *BatchError.
+// With ContinueOnError each operation runs in its own savepoint, failures are reported in their
+// results, and the operations that succeeded are committed together
+func (s *TodoService) Batch(ctx context.Context, req *models.BatchRequest) ([]*models.BatchResult, error) {
+	if len(req.Operations) > maxBatchOperations {
+		return nil, fmt.Errorf("%w: a batch can contain at most %d operations", ErrInvalidInput, maxBatchOperations)
+	}
+
+	results := make([]*models.BatchResult, len(req.Operations))
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		for i := range req.Operations {
+			op := &req.Operations[i]
+			result := &models.BatchResult{Index: i, Op: op.Op, ID: op.ID}
+			results[i] = result
+
+			run := func(ctx context.Context) error {
+				result.Todo, result.Err = s.runBatchOperation(ctx, op)
+				return result.Err
+			}
+
+			if !req.ContinueOnError {
+				if err := run(ctx); err != nil {
+					return &BatchError{Index: i, Err: err}
+				}
+				continue
+			}
+
+			// The failure is already in the result; the savepoint only keeps it from aborting the transaction
+			// Failing to set, release or roll back the savepoint itself fails the operation too
+			if err := s.repo.WithSavepoint(ctx, run); err != nil && result.Err == nil {
+				result.Todo, result.Err = nil, err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Todo != nil {
+			result.ID = result.Todo.ID
+		}
+	}
+
+	return results, nil
+}
+
+// runBatchOperation performs one batch operation through the regular service methods,
+// so it gets the same validation as the single-todo endpoints
+func (s *TodoService) runBatchOperation(ctx context.Context, op *models.BatchOperation) (*models.Todo, error) {
+	var ifMatch []int
+	if op.Version != nil {
+		ifMatch = []int{*op.Version}
+	}
+
+	if op.Op != models.BatchCreate && op.ID == 0 {
+		return nil, fmt.Errorf("%w: %s requires an id", ErrInvalidInput, op.Op)
+	}
+
+	switch op.Op {
+	case models.BatchCreate:
+		if op.Create == nil {
+			return nil, fmt.Errorf("%w: create requires a todo in create", ErrInvalidInput)
+		}
+		return s.Create(ctx, op.Create)
+
+	case models.BatchUpdate:
+		if op.Update == nil {
+			return nil, fmt.Errorf("%w: update requires fields in update", ErrInvalidInput)
+		}
+		update := *op.Update
+		update.IfMatch = ifMatch
+		return s.Update(ctx, op.ID, &update)
+
+	case models.BatchComplete:
+		completed := true
+		return s.Update(ctx, op.ID, &models.UpdateTodoRequest{Completed: &completed, Force: op.Force, IfMatch: ifMatch})
+
+	case models.BatchDelete:
+		return nil, s.Delete(ctx, op.ID, ifMatch)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidInput, op.Op)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+var (
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
+// Dependencies lists what blocks a todo owned by the current user and what it blocks
+// Admins can set allUsers to look at any user's todo
+func (s *TodoService) Dependencies(ctx context.Context, id int, allUsers bool) (*models.TodoDependencies, error) {
+	if _, err := s.GetByID(ctx, id, allUsers); err != nil {
+		return nil, err
+	}
+
+	blockedBy, err := s.repo.ListBlockers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	blocking, err := s.repo.ListBlocking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TodoDependencies{BlockedBy: blockedBy, Blocking: blocking}, nil
+}
+
+// AddDependency declares that a todo is blocked by another todo of the current user
+// Dependencies that would close a cycle are rejected
+func (s *TodoService) AddDependency(ctx context.Context, id int, req *models.AddDependencyRequest) (*models.TodoDependencies, error) {
+	if id == req.BlockedByID {
+		return nil, fmt.Errorf("%w: a todo cannot be blocked by itself", ErrInvalidInput)
+	}
+
+	if _, err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetByID(ctx, req.BlockedByID, false); err != nil {
+		if errors.Is(err, ErrTodoNotFound) {
+			return nil, fmt.Errorf("%w: blocking todo %d not found", ErrInvalidInput, req.BlockedByID)
+		}
+		return nil, err
+	}
+
+	// If the blocker already waits on this todo, directly or transitively,
+	// neither could ever be completed
+	cycle, err := s.repo.DependsOn(ctx, req.BlockedByID, id)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, fmt.Errorf("%w: todo %d already depends on todo %d, so this would create a cycle", ErrInvalidInput, req.BlockedByID, id)
+	}
+
+	if err := s.repo.AddDependency(ctx, id, req.BlockedByID); err != nil {
+		return nil, err
+	}
+
+	return s.Dependencies(ctx, id, false)
+}
+
+// RemoveDependency removes the declaration that a todo is blocked by another
+func (s *TodoService) RemoveDependency(ctx context.Context, id, blockedByID int) error {
+	if _, err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return err
+	}
+
+	err := s.repo.RemoveDependency(ctx, id, blockedByID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDependencyNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+var (
+	// ErrPatchTestFailed means a JSON Patch test operation didn't hold, so nothing was changed
+	ErrPatchTestFailed = errors.New("patch test failed")
+)
+
+// maxPatchAttempts bounds how often a patch without If-Match is reapplied
+// when the todo changes between reading it and writing the result
+const maxPatchAttempts = 3
+
+// Replace overwrites a todo of the current user with a full representation
+// Optional fields missing from the replacement are cleared
+func (s *TodoService) Replace(ctx context.Context, id int, req *models.ReplaceTodoRequest) (*models.Todo, error) {
+	// Counted in characters, like the max tags of the create and update bindings
+	if title := utf8.RuneCountInString(req.Title); title < 1 || title > 200 {
+		return nil, fmt.Errorf("%w: title must be between 1 and 200 characters", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(req.Description) > 1000 {
+		return nil, fmt.Errorf("%w: description must be at most 1000 characters", ErrInvalidInput)
+	}
+	if req.Priority == "" {
+		req.Priority = models.PriorityNone
+	}
+
+	return s.Update(ctx, id, req.UpdateRequest())
+}
+
+// Patch applies a JSON Merge Patch or JSON Patch to the full representation of a todo
+// of the current user and writes the result as a replacement
+func (s *TodoService) Patch(ctx context.Context, id int, req *models.PatchTodoRequest) (*models.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		existing, err := s.GetByID(ctx, id, false)
+		if err != nil {
+			return nil, err
+		}
+		if req.IfMatch != nil && !slices.Contains(req.IfMatch, existing.Version) {
+			return nil, ErrVersionMismatch
+		}
+
+		replacement, err := applyTodoPatch(existing.Replacement(), req.Format, req.Patch)
+		if err != nil {
+			return nil, err
+		}
+		replacement.Force = replacement.Force || req.Force
+		replacement.CompleteSubtasks = replacement.CompleteSubtasks || req.CompleteSubtasks
+
+		// The patch was computed against this version and must not land on a newer one
+		replacement.IfMatch = []int{existing.Version}
+
+		todo, err := s.Replace(ctx, id, replacement)
+		if errors.Is(err, ErrVersionMismatch) && req.IfMatch == nil && attempt < maxPatchAttempts {
+			continue
+		}
+		return todo, err
+	}
+}
+
+// applyTodoPatch applies a patch document to a todo's replacement and decodes the result
+func applyTodoPatch(current *models.ReplaceTodoRequest, format models.PatchFormat, patch []byte) (*models.ReplaceTodoRequest, error) {
+	document, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode todo: %w", err)
+	}
+
+	var patched []byte
+	switch format {
+	case models.MergePatch:
+		patched, err = jsonpatch.MergePatch(document, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid merge patch: %v", ErrInvalidInput, err)
+		}
+	case models.JSONPatch:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON patch: %v", ErrInvalidInput, err)
+		}
+		patched, err = operations.Apply(document)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, fmt.Errorf("%w: %v", ErrPatchTestFailed, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: patch does not apply: %v", ErrInvalidInput, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported patch format %q", ErrInvalidInput, format)
+	}
+
+	// Patches may only touch fields of the representation
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	replacement := &models.ReplaceTodoRequest{}
+	if err := decoder.Decode(replacement); err != nil {
+		return nil, fmt.Errorf("%w: patched todo is invalid: %v", ErrInvalidInput, err)
+	}
+
+	return replacement, nil
+}
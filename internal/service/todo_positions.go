@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/ordering"
+)
+
+// positionRebalanceLength is the key length past which a list is rebalanced
+// Keys grow about one character per 30 moves to the same end of a list
+const positionRebalanceLength = 10
+
+// Move places a todo the current user can edit before and/or after other todos of its list
+func (s *TodoService) Move(ctx context.Context, id int, req *models.MoveTodoRequest) (*models.Todo, error) {
+	if req.Before == nil && req.After == nil {
+		return nil, fmt.Errorf("%w: before or after is required", ErrInvalidInput)
+	}
+
+	todo, err := s.Authorize(ctx, id, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, anchorID := range []*int{req.Before, req.After} {
+		if anchorID != nil {
+			if err := s.checkAnchor(ctx, todo, *anchorID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var moved *models.Todo
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		position, err := s.placement(ctx, id, req)
+		if errors.Is(err, ordering.ErrInvalidKey) || (err == nil && len(position) > ordering.MaxLength) {
+			// Neighbours can share a key after concurrent inserts, and keys grow long after many
+			// moves to the same spot; spreading the list out gives every todo a short key of its own
+			if err := s.repo.Rebalance(ctx, todo.PositionScope()); err != nil {
+				return err
+			}
+			position, err = s.placement(ctx, id, req)
+		}
+		if errors.Is(err, ordering.ErrInvalidKey) && req.Before != nil && req.After != nil {
+			// Distinct keys are always ordered, so the anchors must be the wrong way round
+			return fmt.Errorf("%w: todo %d comes after todo %d in the list", ErrInvalidInput, *req.After, *req.Before)
+		}
+		if err != nil {
+			return err
+		}
+
+		moved, err = s.repo.SetPosition(ctx, id, ownerID, position)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if moved == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return moved, nil
+}
+
+// RebalancePositions gives the lists whose keys have grown long fresh, short keys
+// It returns how many lists were rebalanced
+func (s *TodoService) RebalancePositions(ctx context.Context) (int, error) {
+	scopes, err := s.repo.ScopesToRebalance(ctx, positionRebalanceLength)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		rebalanced int
+		errs       []error
+	)
+	for _, scope := range scopes {
+		if err := s.repo.Rebalance(ctx, scope); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rebalanced++
+	}
+
+	return rebalanced, errors.Join(errs...)
+}
+
+// checkAnchor verifies that a todo named in a move is another todo of the same list the user can see
+func (s *TodoService) checkAnchor(ctx context.Context, todo *models.Todo, anchorID int) error {
+	if anchorID == todo.ID {
+		return fmt.Errorf("%w: a todo cannot be moved relative to itself", ErrInvalidInput)
+	}
+
+	anchor, err := s.GetByID(ctx, anchorID, false)
+	if errors.Is(err, ErrTodoNotFound) {
+		return fmt.Errorf("%w: todo %d does not exist", ErrInvalidInput, anchorID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !todo.PositionScope().Equal(anchor.PositionScope()) {
+		return fmt.Errorf("%w: todo %d is in a different list", ErrInvalidInput, anchorID)
+	}
+
+	return nil
+}
+
+// placement works out the key that puts todo id where req asks, reading the anchors' current keys
+// When only one anchor is given, the todo goes between it and its neighbour on the other side
+func (s *TodoService) placement(ctx context.Context, id int, req *models.MoveTodoRequest) (string, error) {
+	var lower, upper string
+
+	if req.After != nil {
+		after, err := s.repo.GetByID(ctx, *req.After, nil)
+		if err != nil {
+			return "", err
+		}
+		if after == nil {
+			return "", ErrTodoNotFound
+		}
+		lower = after.Position
+		if req.Before == nil {
+			if upper, err = s.repo.AdjacentPosition(ctx, after, true, id); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if req.Before != nil {
+		before, err := s.repo.GetByID(ctx, *req.Before, nil)
+		if err != nil {
+			return "", err
+		}
+		if before == nil {
+			return "", ErrTodoNotFound
+		}
+		upper = before.Position
+		if req.After == nil {
+			if lower, err = s.repo.AdjacentPosition(ctx, before, false, id); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return ordering.Between(lower, upper)
+}
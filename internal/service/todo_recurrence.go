@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/recurrence"
+)
+
+// SetRecurrence makes a todo the current user can edit repeat, or changes the rule of its series
+// The rule starts counting from the todo's current due date
+func (s *TodoService) SetRecurrence(ctx context.Context, id int, req *models.RecurrenceRequest) (*models.Todo, error) {
+	todo, err := s.Authorize(ctx, id, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Completed {
+		return nil, fmt.Errorf("%w: completed todos cannot repeat; set the rule on the open occurrence", ErrInvalidInput)
+	}
+
+	rec, err := resolveRecurrence(ctx, req, todo.DueAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.SetRecurrence(ctx, id, ownerID, rec, nil)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return updated, nil
+}
+
+// StopRecurrence ends a todo's series: the todo stays, but completing it no longer creates another
+func (s *TodoService) StopRecurrence(ctx context.Context, id int) (*models.Todo, error) {
+	if _, err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.SetRecurrence(ctx, id, ownerID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return todo, nil
+}
+
+// PreviewRecurrence lists the next count occurrences of a recurring todo after its current due date
+// Admins can set allUsers to look at any user's todo
+func (s *TodoService) PreviewRecurrence(ctx context.Context, id, count int, allUsers bool) (*models.RecurrencePreview, error) {
+	if count < 1 || count > recurrence.MaxPreview {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidInput, recurrence.MaxPreview)
+	}
+
+	todo, err := s.GetByID(ctx, id, allUsers)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Recurrence == nil {
+		return nil, fmt.Errorf("%w: todo %d does not repeat", ErrInvalidInput, id)
+	}
+
+	rule, err := parseStoredRecurrence(todo.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	after := time.Now()
+	if todo.DueAt != nil {
+		after = *todo.DueAt
+	}
+
+	return &models.RecurrencePreview{
+		Rule:        todo.Recurrence.Rule,
+		Timezone:    todo.Recurrence.Timezone,
+		Occurrences: rule.Next(after, count),
+	}, nil
+}
+
+// spawnNextOccurrence creates the occurrence that follows a just-completed recurring todo
+// and moves the series' rule over to it. When the rule has no further occurrences the
+// series simply ends. Call it inside the transaction that completed the todo
+func (s *TodoService) spawnNextOccurrence(ctx context.Context, completed *models.Todo, ownerID *int) error {
+	rec := completed.Recurrence
+
+	rule, err := parseStoredRecurrence(rec)
+	if err != nil {
+		return err
+	}
+
+	// The completed occurrence keeps its place in the series but no longer carries the rule
+	stopped, err := s.repo.SetRecurrence(ctx, completed.ID, ownerID, nil, nil)
+	if err != nil {
+		return err
+	}
+	*completed = *stopped
+
+	anchor := time.Now()
+	if completed.DueAt != nil {
+		anchor = *completed.DueAt
+	}
+	dueAt, ok := rule.After(anchor)
+	if !ok {
+		return nil
+	}
+
+	// Keep the same lead time between start and due date
+	var startAt *time.Time
+	if completed.StartAt != nil && completed.DueAt != nil {
+		start := dueAt.Add(-completed.DueAt.Sub(*completed.StartAt))
+		startAt = &start
+	}
+
+	// The series stays with its owner, tags included, whoever completed this occurrence
+	next, err := s.repo.Create(ctx, &models.CreateTodoRequest{
+		Title:       completed.Title,
+		Description: completed.Description,
+		DueAt:       &dueAt,
+		StartAt:     startAt,
+		Priority:    completed.Priority,
+		Tags:        completed.Tags,
+		ProjectID:   completed.ProjectID,
+		ParentID:    completed.ParentID,
+		AssigneeID:  completed.AssigneeID,
+		CreatedBy:   completed.CreatedBy,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.checkWIPLimit(ctx, nil, next); err != nil {
+		return err
+	}
+
+	// The new occurrence may not be visible to whoever completed the last one, such as an editor
+	// of just that todo, but it was created in this very transaction
+	next, err = s.repo.SetRecurrence(ctx, next.ID, nil, rec, completed.SeriesID)
+	if err != nil {
+		return err
+	}
+
+	completed.NextOccurrence = next
+	return nil
+}
+
+// resolveRecurrence validates a recurrence request for a todo due at dueAt
+// The rule is evaluated in the requested timezone, falling back to the user's own
+func resolveRecurrence(ctx context.Context, req *models.RecurrenceRequest, dueAt *time.Time) (*models.Recurrence, error) {
+	if dueAt == nil {
+		return nil, fmt.Errorf("%w: recurring todos need a due_at to count occurrences from", ErrInvalidInput)
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = models.GetUserFromContext(ctx).Location().String()
+	}
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, timezone)
+	}
+
+	rule, err := recurrence.Parse(req.Rule, *dueAt, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	return &models.Recurrence{
+		Rule:     rule.String(),
+		Timezone: loc.String(),
+		Start:    *dueAt,
+	}, nil
+}
+
+// parseStoredRecurrence parses a rule that was validated when it was saved
+func parseStoredRecurrence(rec *models.Recurrence) (*recurrence.Rule, error) {
+	loc, err := time.LoadLocation(rec.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recurrence timezone: %w", err)
+	}
+
+	rule, err := recurrence.Parse(rec.Rule, rec.Start, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored recurrence: %w", err)
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+)
+
+// History lists the revisions of a todo owned by the current user, newest first
+// Admins can set allUsers to look at any user's todo
+func (s *TodoService) History(ctx context.Context, id, page, pageSize int, allUsers bool) ([]*models.TodoRevision, int, error) {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	if _, err := s.GetByID(ctx, id, allUsers); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.ListRevisions(ctx, id, offset, pageSize)
+}
+
+// Revision retrieves one revision of a todo owned by the current user, including the todo's state after it
+func (s *TodoService) Revision(ctx context.Context, id, rev int, allUsers bool) (*models.TodoRevision, error) {
+	if _, err := s.GetByID(ctx, id, allUsers); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.repo.GetRevision(ctx, id, rev)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	return revision, nil
+}
+
+// Revert restores a todo the current user can edit to the state it had right after a revision
+// The revert is itself recorded as a new revision, so it can be undone the same way
+func (s *TodoService) Revert(ctx context.Context, id, rev int) (*models.Todo, error) {
+	existing, err := s.Authorize(ctx, id, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.repo.GetRevision(ctx, id, rev)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, ErrRevisionNotFound
+	}
+	snapshot := revision.Snapshot
+
+	// The projects, parents and blockers of the past may have changed since,
+	// so the old state has to pass the same checks as an update
+	if err := s.checkMove(ctx, existing, snapshot.ProjectID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.ParentID != nil && !sameID(snapshot.ParentID, existing.ParentID) {
+		if err := s.checkParent(ctx, id, *snapshot.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !sameID(snapshot.AssigneeID, existing.AssigneeID) {
+		assigneeID := 0
+		if snapshot.AssigneeID != nil {
+			assigneeID = *snapshot.AssigneeID
+		}
+		if err := s.changeAssignee(ctx, existing, assigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Going back to an earlier state of the same workflow is a move like any other
+	if snapshot.StatusID != nil && existing.StatusID != nil && !sameID(snapshot.StatusID, existing.StatusID) &&
+		sameID(snapshot.ProjectID, existing.ProjectID) && existing.ProjectID != nil {
+		if err := s.checkTransition(ctx, *existing.ProjectID, *existing.StatusID, *snapshot.StatusID); err != nil {
+			return nil, err
+		}
+	}
+
+	if snapshot.Completed && !existing.Completed {
+		if existing.Recurrence != nil {
+			return nil, fmt.Errorf("%w: complete a recurring todo with an update so its next occurrence is created", ErrInvalidInput)
+		}
+
+		open, err := s.repo.CountOpenBlockers(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, fmt.Errorf("%w: %d blocking todo(s) still open", ErrTodoBlocked, open)
+		}
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *models.Todo
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if todo, err = s.repo.Revert(ctx, id, ownerID, snapshot); err != nil {
+			return err
+		}
+		return s.checkWIPLimit(ctx, existing.StatusID, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return todo, nil
+}
+
+// sameID reports whether two optional IDs are both unset or equal
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// Authorize loads a todo the current user can see and holds at least the given role on
+// Anyone who can see a todo is at least a viewer; see roleOn for the other roles
+func (s *TodoService) Authorize(ctx context.Context, id int, role models.ShareRole) (*models.Todo, error) {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.GetByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := s.roleOn(ctx, todo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !held.Includes(role) {
+		return nil, fmt.Errorf("%w: this requires %s access to the todo", ErrForbidden, role)
+	}
+
+	return todo, nil
+}
+
+// roleOn works out a user's role on a todo they can see: its creator and the owner of
+// its project own it, its assignee can edit it, and everyone else has the highest role
+// shared with them on the todo or its project
+func (s *TodoService) roleOn(ctx context.Context, todo *models.Todo, userID int) (models.ShareRole, error) {
+	if sameID(todo.CreatedBy, &userID) {
+		return models.RoleOwner, nil
+	}
+
+	if todo.ProjectID != nil {
+		project, err := s.projectRepo.GetByID(ctx, *todo.ProjectID, userID)
+		if err != nil {
+			return "", err
+		}
+		if project != nil {
+			return models.RoleOwner, nil
+		}
+	}
+
+	role, err := s.shareRepo.TodoRole(ctx, todo.ID, todo.ProjectID, userID)
+	if err != nil {
+		return "", err
+	}
+	if sameID(todo.AssigneeID, &userID) && !role.Includes(models.RoleEditor) {
+		role = models.RoleEditor
+	}
+	if role == "" {
+		role = models.RoleViewer
+	}
+
+	return role, nil
+}
+
+// authorizeAuthor decides whether the current user may change something authorID added to a
+// todo, such as a comment or a time entry: only its author can, or any admin when allowAdmin
+// is set. Anyone else gets forbidden, unless they can't see the todo either, in which case
+// they get notFound so they don't learn what the todo holds
+func (s *TodoService) authorizeAuthor(ctx context.Context, todoID int, authorID *int, allowAdmin bool, notFound, forbidden error) error {
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if sameID(authorID, &user.ID) || (allowAdmin && user.IsAdmin) {
+		return nil
+	}
+
+	if _, err := s.GetByID(ctx, todoID, false); err != nil {
+		if errors.Is(err, ErrTodoNotFound) {
+			return notFound
+		}
+		return err
+	}
+	return forbidden
+}
+
+// checkMove verifies that the current user may file a todo under another project, or take it
+// out to the inbox when projectID is nil. Only its owners can: the owner of the destination
+// project owns the todo afterwards, so an editor could otherwise take it over
+func (s *TodoService) checkMove(ctx context.Context, todo *models.Todo, projectID *int) error {
+	if sameID(projectID, todo.ProjectID) {
+		return nil
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleOn(ctx, todo, user.ID)
+	if err != nil {
+		return err
+	}
+	if !role.Includes(models.RoleOwner) {
+		return fmt.Errorf("%w: only the owner of a todo can move it to another project", ErrForbidden)
+	}
+
+	if projectID != nil {
+		return s.checkProject(ctx, *projectID)
+	}
+	return nil
+}
+
+// AuthorizeProject loads a project the current user owns or that is shared with them
+// with at least the given role; its owner holds RoleOwner
+func (s *TodoService) AuthorizeProject(ctx context.Context, projectID int, role models.ShareRole) (*models.Project, error) {
+	if projectID <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, held, err := s.projectRole(ctx, projectID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+	if !held.Includes(role) {
+		return nil, fmt.Errorf("%w: this requires %s access to the project", ErrForbidden, role)
+	}
+
+	return project, nil
+}
+
+// projectRole loads a project the user owns or that is shared with them, together with
+// their role on it; a nil project means they have no access
+func (s *TodoService) projectRole(ctx context.Context, projectID, userID int) (*models.Project, models.ShareRole, error) {
+	project, err := s.projectRepo.GetAccessible(ctx, projectID, userID)
+	if err != nil || project == nil {
+		return nil, "", err
+	}
+	if project.UserID == userID {
+		return project, models.RoleOwner, nil
+	}
+
+	role, err := s.shareRepo.ProjectRole(ctx, projectID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return project, role, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+var (
+	ErrWIPLimitReached = errors.New("work in progress limit reached")
+)
+
+// checkCreateStatus validates the state a new todo asks to start in
+// Without one, it starts in the first open state of its project's workflow
+func (s *TodoService) checkCreateStatus(ctx context.Context, req *models.CreateTodoRequest) error {
+	if req.StatusID == nil {
+		return nil
+	}
+
+	state, err := s.workflowState(ctx, req.ProjectID, *req.StatusID)
+	if err != nil {
+		return err
+	}
+	if state.Terminal {
+		return fmt.Errorf("%w: new todos cannot start in the terminal state %q", ErrInvalidInput, state.Name)
+	}
+
+	return nil
+}
+
+// resolveUpdateStatus works out the state an update takes a todo to and completes or reopens
+// the todo to match: moving to a terminal state completes it, completing or reopening it
+// without naming a state picks the first matching one. Moves within a workflow have to be
+// allowed transitions; a todo entering a new project's workflow can start in any state
+func (s *TodoService) resolveUpdateStatus(ctx context.Context, existing *models.Todo, req *models.UpdateTodoRequest) error {
+	projectID := existing.ProjectID
+	if req.ProjectID != nil {
+		projectID = nil
+		if *req.ProjectID != 0 {
+			projectID = req.ProjectID
+		}
+	}
+	moving := !sameID(projectID, existing.ProjectID)
+
+	// Full replacements and patches echo the current state, which isn't a move
+	if !moving && sameID(req.StatusID, existing.StatusID) {
+		req.StatusID = nil
+	}
+
+	var target *models.WorkflowState
+	switch {
+	case req.StatusID != nil:
+		state, err := s.workflowState(ctx, projectID, *req.StatusID)
+		if err != nil {
+			return err
+		}
+		if req.Completed != nil && *req.Completed != state.Terminal {
+			return fmt.Errorf("%w: completed must be %t in the state %q", ErrInvalidInput, state.Terminal, state.Name)
+		}
+		target = state
+	case req.Completed != nil && *req.Completed != existing.Completed && !moving && projectID != nil:
+		workflow, err := s.workflowRepo.Get(ctx, *projectID)
+		if err != nil {
+			return err
+		}
+		if target = workflow.InitialState(*req.Completed); target == nil {
+			return nil
+		}
+	default:
+		// A todo changing project enters the new workflow where its completion puts it
+		return nil
+	}
+
+	if !moving && existing.StatusID != nil {
+		if err := s.checkTransition(ctx, target.ProjectID, *existing.StatusID, target.ID); err != nil {
+			return err
+		}
+	}
+
+	completed := target.Terminal
+	req.StatusID = &target.ID
+	req.Completed = &completed
+	return nil
+}
+
+// workflowState looks up a state of the workflow of the project a todo is or will be in
+func (s *TodoService) workflowState(ctx context.Context, projectID *int, stateID int) (*models.WorkflowState, error) {
+	if projectID == nil {
+		return nil, fmt.Errorf("%w: only todos in a project with a workflow have a status", ErrInvalidInput)
+	}
+
+	workflow, err := s.workflowRepo.Get(ctx, *projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := workflow.State(stateID)
+	if state == nil {
+		return nil, fmt.Errorf("%w: state %d is not part of the workflow of project %d", ErrInvalidInput, stateID, *projectID)
+	}
+
+	return state, nil
+}
+
+// checkTransition verifies that a project's workflow allows moving a todo from one state to another
+func (s *TodoService) checkTransition(ctx context.Context, projectID, from, to int) error {
+	workflow, err := s.workflowRepo.Get(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if workflow.Allows(from, to) {
+		return nil
+	}
+
+	source, target := "its current state", fmt.Sprintf("state %d", to)
+	if current := workflow.State(from); current != nil {
+		source = fmt.Sprintf("%q", current.Name)
+	}
+	if state := workflow.State(to); state != nil {
+		target = fmt.Sprintf("%q", state.Name)
+	}
+	return fmt.Errorf("%w: the workflow does not allow moving from %s to %s", ErrInvalidInput, source, target)
+}
+
+// checkWIPLimit verifies that the state a todo was just written into hasn't gone over its limit
+// A todo that was already in the state before the write doesn't count against it
+func (s *TodoService) checkWIPLimit(ctx context.Context, previousStatusID *int, todo *models.Todo) error {
+	if todo == nil || todo.StatusID == nil || sameID(previousStatusID, todo.StatusID) {
+		return nil
+	}
+	return s.checkStateLimit(ctx, *todo.StatusID)
+}
+
+// checkStateLimit verifies that a state todos were just written into hasn't gone over its limit
+// It has to run in the transaction of the write: the state is locked before its todos are
+// counted, so concurrent writes into it are counted one after the other
+func (s *TodoService) checkStateLimit(ctx context.Context, stateID int) error {
+	state, err := s.workflowRepo.LockState(ctx, stateID)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.WIPLimit == nil {
+		return nil
+	}
+
+	count, err := s.repo.CountInState(ctx, state.ID)
+	if err != nil {
+		return err
+	}
+	if count > *state.WIPLimit {
+		return fmt.Errorf("%w: %q already holds its limit of %d todos", ErrWIPLimitReached, state.Name, *state.WIPLimit)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swusjask/todo-api/internal/models"
+)
+
+// ListTrash retrieves the deleted todos the current user owns, most recently deleted first
+func (s *TodoService) ListTrash(ctx context.Context, page, pageSize int) ([]*models.Todo, int, error) {
+	filter := &models.TodoFilter{Deleted: true}
+	sort := []models.SortField{{Field: "deleted_at", Desc: true}}
+	return s.List(ctx, filter, sort, page, pageSize, false)
+}
+
+// Restore brings a todo back from the trash; like deleting it, that takes owner access
+func (s *TodoService) Restore(ctx context.Context, id int) (*models.Todo, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid ID", ErrInvalidInput)
+	}
+
+	user, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerScope(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.repo.GetDeleted(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	role, err := s.roleOn(ctx, deleted, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.RoleOwner {
+		return nil, fmt.Errorf("%w: only the owners of a todo can restore it", ErrForbidden)
+	}
+
+	// A restored todo counts against the limit of its state again
+	var todo *models.Todo
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if todo, err = s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+		return s.checkWIPLimit(ctx, nil, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	return todo, nil
+}
+
+// PurgeTrash permanently deletes todos that have been in the trash for longer than retention,
+// along with the files attached to them
+// Blobs that fail to delete are reported in the error, but don't undo the purge
+func (s *TodoService) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, keys, err := s.repo.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete attachment blob %s: %w", key, err))
+		}
+	}
+
+	return purged, errors.Join(errs...)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/swusjask/todo-api/internal/models"
+	"github.com/swusjask/todo-api/internal/repository"
+)
+
+// WorkflowService contains business logic for project workflows and their boards
+type WorkflowService struct {
+	repo     *repository.WorkflowRepository
+	todoRepo *repository.TodoRepository
+	todos    *TodoService
+}
+
+// NewWorkflowService creates a new workflow service
+// Access to a project's workflow follows the roles decided by todos
+func NewWorkflowService(repo *repository.WorkflowRepository, todoRepo *repository.TodoRepository, todos *TodoService) *WorkflowService {
+	return &WorkflowService{repo: repo, todoRepo: todoRepo, todos: todos}
+}
+
+// Get retrieves the workflow of a project the current user has access to
+func (s *WorkflowService) Get(ctx context.Context, projectID int) (*models.Workflow, error) {
+	if _, err := s.todos.AuthorizeProject(ctx, projectID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Get(ctx, projectID)
+}
+
+// Replace sets the workflow of a project the current user owns
+// Todos move along: those in removed states, or in states whose terminal flag no longer
+// matches their completion, go to the first state that does, as long as that keeps it
+// within its WIP limit. States already over their limit, say after lowering it, stay so
+func (s *WorkflowService) Replace(ctx context.Context, projectID int, req *models.WorkflowRequest) (*models.Workflow, error) {
+	if _, err := s.todos.AuthorizeProject(ctx, projectID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.Get(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*models.WorkflowState, len(req.States))
+	index := make(map[string]int, len(req.States))
+	terminal, open := false, false
+	for i, stateReq := range req.States {
+		name := strings.TrimSpace(stateReq.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: state names must not be empty", ErrInvalidInput)
+		}
+		key := strings.ToLower(name)
+		if _, ok := index[key]; ok {
+			return nil, fmt.Errorf("%w: state %q appears more than once", ErrInvalidInput, name)
+		}
+		index[key] = i
+
+		state := &models.WorkflowState{ProjectID: projectID, Name: name, Terminal: stateReq.Terminal, WIPLimit: stateReq.WIPLimit}
+		if stateReq.ID != nil {
+			if current.State(*stateReq.ID) == nil {
+				return nil, fmt.Errorf("%w: state %d is not part of this workflow", ErrInvalidInput, *stateReq.ID)
+			}
+			for _, other := range states[:i] {
+				if other.ID == *stateReq.ID {
+					return nil, fmt.Errorf("%w: state %d appears more than once", ErrInvalidInput, *stateReq.ID)
+				}
+			}
+			state.ID = *stateReq.ID
+		}
+		states[i] = state
+
+		terminal = terminal || state.Terminal
+		open = open || !state.Terminal
+	}
+
+	// Todos have to be able to both be open and completed in a workflow
+	if len(states) > 0 && !(terminal && open) {
+		return nil, fmt.Errorf("%w: a workflow needs at least one terminal and one non-terminal state", ErrInvalidInput)
+	}
+
+	var transitions [][2]int
+	seen := map[[2]int]bool{}
+	for _, transitionReq := range req.Transitions {
+		from, ok := index[strings.ToLower(strings.TrimSpace(transitionReq.From))]
+		if !ok {
+			return nil, fmt.Errorf("%w: transition from unknown state %q", ErrInvalidInput, transitionReq.From)
+		}
+		to, ok := index[strings.ToLower(strings.TrimSpace(transitionReq.To))]
+		if !ok {
+			return nil, fmt.Errorf("%w: transition to unknown state %q", ErrInvalidInput, transitionReq.To)
+		}
+		if from == to {
+			return nil, fmt.Errorf("%w: a state cannot transition to itself", ErrInvalidInput)
+		}
+
+		transition := [2]int{from, to}
+		if !seen[transition] {
+			seen[transition] = true
+			transitions = append(transitions, transition)
+		}
+	}
+
+	err = s.todoRepo.WithTx(ctx, func(ctx context.Context) error {
+		changed, err := s.todoRepo.SyncProjectStatuses(ctx, projectID, func(ctx context.Context) error {
+			return s.repo.Replace(ctx, projectID, states, transitions)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Lock the states in a fixed order, so two replacements can't wait on each other
+		var entered []int
+		for _, statusID := range changed {
+			if statusID != nil && !slices.Contains(entered, *statusID) {
+				entered = append(entered, *statusID)
+			}
+		}
+		slices.Sort(entered)
+		for _, stateID := range entered {
+			if err := s.todos.checkStateLimit(ctx, stateID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Get(ctx, projectID)
+}
+
+// Board groups the todos of a project with a workflow by state, listing up to limit todos
+// per state in manual order together with how many the state holds
+func (s *WorkflowService) Board(ctx context.Context, projectID, limit int) (*models.Board, error) {
+	if _, err := s.todos.AuthorizeProject(ctx, projectID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.repo.Get(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(workflow.States) == 0 {
+		return nil, fmt.Errorf("%w: project %d has no workflow", ErrInvalidInput, projectID)
+	}
+
+	counts, err := s.todoRepo.CountByState(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, limit = NormalizePagination(1, limit)
+	sort := []models.SortField{{Field: "position"}}
+
+	board := &models.Board{ProjectID: projectID, Columns: make([]*models.BoardColumn, 0, len(workflow.States))}
+	for _, state := range workflow.States {
+		filter := &models.TodoFilter{ProjectID: &projectID, StatusID: &state.ID}
+		todos, _, err := s.todoRepo.List(ctx, filter, sort, 0, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		count := counts[state.ID]
+		board.Columns = append(board.Columns, &models.BoardColumn{
+			State:     state,
+			Count:     count,
+			OverLimit: state.WIPLimit != nil && count > *state.WIPLimit,
+			Todos:     todos,
+		})
+	}
+
+	return board, nil
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+// BlobStore keeps the content of uploaded files under opaque keys
+// Implementations must be safe for concurrent use; the local filesystem is the only one so far,
+// an S3-compatible store only has to provide seekable readers (e.g. through range requests)
+type BlobStore interface {
+	// Put stores everything read from r under key and returns the number of bytes written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns a seekable reader over the blob, so downloads can serve byte ranges
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
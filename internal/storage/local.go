@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files below a root directory
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at dir, creating the directory if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve blob directory: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put writes the blob to a temporary file first, so readers never see a partial blob
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return written, nil
+}
+
+// Open opens the blob's file for reading
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the blob's file
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would escape it
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") || filepath.IsAbs(key) {
+		return "", ErrInvalidKey
+	}
+
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+
+	return path, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorePath(t *testing.T) {
+	store := &LocalStore{root: t.TempDir()}
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"generated key", "todos/1/abc", "todos/1/abc"},
+		{"dot segments inside the root", "todos/1/../2/abc", "todos/2/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := store.path(tt.key)
+			if err != nil {
+				t.Fatalf("path(%q) returned error: %v", tt.key, err)
+			}
+			if want := filepath.Join(store.root, filepath.FromSlash(tt.want)); path != want {
+				t.Errorf("path(%q) = %q, want %q", tt.key, path, want)
+			}
+		})
+	}
+}
+
+func TestLocalStorePathInvalid(t *testing.T) {
+	store := &LocalStore{root: t.TempDir()}
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"empty", ""},
+		{"parent directory", "../abc"},
+		{"escaping through a subdirectory", "todos/../../abc"},
+		{"the root itself", "todos/.."},
+		{"dot", "."},
+		{"absolute path", "/etc/passwd"},
+		{"backslashes", "todos\\1\\abc"},
+		{"backslash traversal", "..\\abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path, err := store.path(tt.key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("path(%q) = %q, %v, want ErrInvalidKey", tt.key, path, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+-- Remove todo sort indexes
+
+DROP INDEX IF EXISTS idx_todos_updated_at;
+DROP INDEX IF EXISTS idx_todos_created_by_title;
+DROP INDEX IF EXISTS idx_todos_created_by_updated_at;
+DROP INDEX IF EXISTS idx_todos_created_by_created_at;
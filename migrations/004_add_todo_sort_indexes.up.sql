@@ -0,0 +1,9 @@
+-- Add indexes backing the sortable todo listings
+-- Every listing is scoped to created_by and tiebroken on id, so both are part of each index
+
+CREATE INDEX idx_todos_created_by_created_at ON todos(created_by, created_at DESC, id DESC);
+CREATE INDEX idx_todos_created_by_updated_at ON todos(created_by, updated_at DESC, id DESC);
+CREATE INDEX idx_todos_created_by_title ON todos(created_by, LOWER(title), id);
+
+-- Admin listings across all users are not scoped to created_by
+CREATE INDEX idx_todos_updated_at ON todos(updated_at DESC, id DESC);
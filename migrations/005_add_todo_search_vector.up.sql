@@ -0,0 +1,12 @@
+-- Add full-text search over todo titles and descriptions
+
+-- Generated column keeps the vector in sync without triggers
+-- Titles weigh more than descriptions when ranking matches
+ALTER TABLE todos
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+-- GIN index makes @@ matches fast regardless of table size
+CREATE INDEX idx_todos_search_vector ON todos USING GIN (search_vector);
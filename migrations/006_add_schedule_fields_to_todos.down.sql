@@ -0,0 +1,13 @@
+-- Remove due and start dates from todos, and the timezone from users
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
+
+DROP INDEX IF EXISTS idx_todos_created_by_due_at;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_start_before_due;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS due_at,
+DROP COLUMN IF EXISTS start_at;
@@ -0,0 +1,16 @@
+-- Add due and start dates to todos, and a timezone to users
+
+-- TIMESTAMPTZ so instants stay unambiguous across users in different timezones
+ALTER TABLE todos
+ADD COLUMN due_at TIMESTAMPTZ,
+ADD COLUMN start_at TIMESTAMPTZ;
+
+ALTER TABLE todos
+ADD CONSTRAINT chk_todos_start_before_due CHECK (start_at IS NULL OR due_at IS NULL OR start_at <= due_at);
+
+-- Supports due date filters (overdue, due_today, due_within) and sorting by due date
+CREATE INDEX idx_todos_created_by_due_at ON todos(created_by, due_at) WHERE due_at IS NOT NULL;
+
+-- IANA timezone name used to evaluate date-relative filters such as "due today"
+ALTER TABLE users
+ADD COLUMN timezone VARCHAR(64) DEFAULT 'UTC' NOT NULL;
@@ -0,0 +1,9 @@
+-- Remove priority from todos
+
+DROP INDEX IF EXISTS idx_todos_created_by_priority;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_priority;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,11 @@
+-- Add priority to todos
+
+-- Stored as a rank (0 = none, 1 = low, 2 = medium, 3 = high, 4 = urgent) so it sorts naturally
+ALTER TABLE todos
+ADD COLUMN priority SMALLINT DEFAULT 0 NOT NULL;
+
+ALTER TABLE todos
+ADD CONSTRAINT chk_todos_priority CHECK (priority BETWEEN 0 AND 4);
+
+-- Supports the priority sort mode, which breaks ties by due date
+CREATE INDEX idx_todos_created_by_priority ON todos(created_by, priority DESC, due_at);
@@ -0,0 +1,6 @@
+-- Drop tags and the todo_tags join table
+
+DROP TABLE IF EXISTS todo_tags;
+
+DROP TRIGGER IF EXISTS update_tags_updated_at ON tags;
+DROP TABLE IF EXISTS tags;
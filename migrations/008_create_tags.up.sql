@@ -0,0 +1,28 @@
+-- Create tags and the todo_tags join table
+
+-- Tags are private to each user
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) DEFAULT '' NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+-- Tag names are unique per user regardless of case ("Work" and "work" are the same tag)
+CREATE UNIQUE INDEX idx_tags_user_id_name ON tags(user_id, LOWER(name));
+
+CREATE TRIGGER update_tags_updated_at
+    BEFORE UPDATE ON tags
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- The primary key covers lookups by todo; this one covers lookups by tag
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags(tag_id);
@@ -0,0 +1,9 @@
+-- Remove projects and project_id from todos
+
+DROP INDEX IF EXISTS idx_todos_project_id;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS project_id;
+
+DROP TRIGGER IF EXISTS update_projects_updated_at ON projects;
+DROP TABLE IF EXISTS projects;
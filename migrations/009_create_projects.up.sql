@@ -0,0 +1,29 @@
+-- Create projects and add project_id to todos
+
+-- Projects are private to each user; parent_id nests a project inside another like a folder
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7) DEFAULT '' NOT NULL,
+    archived BOOLEAN DEFAULT FALSE NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT chk_projects_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE INDEX idx_projects_user_id ON projects(user_id);
+CREATE INDEX idx_projects_parent_id ON projects(parent_id);
+
+CREATE TRIGGER update_projects_updated_at
+    BEFORE UPDATE ON projects
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- NULL means the todo is in the inbox; deleting a project moves its todos there
+-- unless the application deletes them first
+ALTER TABLE todos
+ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_project_id ON todos(project_id);
@@ -0,0 +1,9 @@
+-- Remove parent_id from todos
+
+DROP INDEX IF EXISTS idx_todos_parent_id;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_not_own_parent;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,11 @@
+-- Add parent_id to todos so todos can have subtasks
+
+-- Deleting a todo deletes its whole subtask tree
+ALTER TABLE todos
+ADD COLUMN parent_id INTEGER REFERENCES todos(id) ON DELETE CASCADE;
+
+ALTER TABLE todos
+ADD CONSTRAINT chk_todos_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id);
+
+-- Supports listing children and walking the subtask tree with recursive queries
+CREATE INDEX idx_todos_parent_id ON todos(parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Drop todo_dependencies
+
+DROP TABLE IF EXISTS todo_dependencies;
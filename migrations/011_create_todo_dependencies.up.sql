@@ -0,0 +1,13 @@
+-- Create todo_dependencies for "blocked by" relations between todos
+
+-- A row means todo_id cannot be completed until blocked_by_id is
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (todo_id, blocked_by_id),
+    CONSTRAINT chk_todo_dependencies_not_self CHECK (todo_id <> blocked_by_id)
+);
+
+-- The primary key covers "what blocks this todo"; this one covers "what does this todo block"
+CREATE INDEX idx_todo_dependencies_blocked_by_id ON todo_dependencies(blocked_by_id);
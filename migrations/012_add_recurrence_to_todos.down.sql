@@ -0,0 +1,12 @@
+-- Remove recurrence rules from todos
+
+DROP INDEX IF EXISTS idx_todos_series_id;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_recurrence;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS series_id,
+DROP COLUMN IF EXISTS recurrence_start,
+DROP COLUMN IF EXISTS recurrence_tz,
+DROP COLUMN IF EXISTS recurrence_rule;
@@ -0,0 +1,17 @@
+-- Add recurrence rules to todos
+
+-- recurrence_rule is an RFC 5545 RRULE evaluated in recurrence_tz from recurrence_start (DTSTART)
+-- Only the open occurrence of a series carries the rule; series_id links all occurrences
+ALTER TABLE todos
+ADD COLUMN recurrence_rule TEXT,
+ADD COLUMN recurrence_tz VARCHAR(64),
+ADD COLUMN recurrence_start TIMESTAMPTZ,
+ADD COLUMN series_id INTEGER REFERENCES todos(id) ON DELETE SET NULL;
+
+ALTER TABLE todos
+ADD CONSTRAINT chk_todos_recurrence CHECK (
+    (recurrence_rule IS NULL AND recurrence_tz IS NULL AND recurrence_start IS NULL)
+    OR (recurrence_rule IS NOT NULL AND recurrence_tz IS NOT NULL AND recurrence_start IS NOT NULL)
+);
+
+CREATE INDEX idx_todos_series_id ON todos(series_id) WHERE series_id IS NOT NULL;
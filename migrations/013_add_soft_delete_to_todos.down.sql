@@ -0,0 +1,7 @@
+-- Remove soft delete from todos
+
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS deleted_by,
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+-- Add soft delete to todos
+
+-- Deleted todos keep their row until the trash retention period has passed
+-- A todo and the subtasks deleted with it share the same deleted_at
+ALTER TABLE todos
+ADD COLUMN deleted_at TIMESTAMP,
+ADD COLUMN deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_deleted_at ON todos(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Drop todo revision history
+
+DROP TABLE IF EXISTS todo_revisions;
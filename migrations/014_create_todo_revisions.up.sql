@@ -0,0 +1,17 @@
+-- Create todo_revisions to record the history of every todo
+
+-- changes maps each changed field to its {"before", "after"} JSON values;
+-- snapshot is the todo's tracked state right after the revision, which revert restores
+CREATE TABLE IF NOT EXISTS todo_revisions (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    rev INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    changes JSONB NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT chk_todo_revisions_action CHECK (action IN ('create', 'update', 'delete', 'restore', 'revert'))
+);
+
+CREATE UNIQUE INDEX idx_todo_revisions_todo_rev ON todo_revisions(todo_id, rev);
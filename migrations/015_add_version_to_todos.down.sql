@@ -0,0 +1,4 @@
+-- Remove the version from todos
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS version;
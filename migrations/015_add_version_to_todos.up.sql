@@ -0,0 +1,5 @@
+-- Add a version to todos for optimistic concurrency control
+
+-- Every write increments version; clients send it back in If-Match to detect lost updates
+ALTER TABLE todos
+ADD COLUMN version INTEGER DEFAULT 1 NOT NULL;
@@ -0,0 +1,4 @@
+-- Drop todo comments
+
+DROP TRIGGER IF EXISTS update_todo_comments_updated_at ON todo_comments;
+DROP TABLE IF EXISTS todo_comments;
@@ -0,0 +1,19 @@
+-- Create todo_comments for discussions on todos
+
+CREATE TABLE IF NOT EXISTS todo_comments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+-- Comments are listed per todo in posting order, and counted per todo for listings
+CREATE INDEX idx_todo_comments_todo_id ON todo_comments(todo_id, created_at, id);
+
+CREATE TRIGGER update_todo_comments_updated_at
+    BEFORE UPDATE ON todo_comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
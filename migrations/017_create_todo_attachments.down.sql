@@ -0,0 +1,3 @@
+-- Drop todo attachments
+
+DROP TABLE IF EXISTS todo_attachments;
@@ -0,0 +1,15 @@
+-- Create todo_attachments for files uploaded to todos
+
+-- Only metadata is stored here; storage_key locates the content in the blob store
+CREATE TABLE IF NOT EXISTS todo_attachments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    uploaded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_todo_attachments_todo_id ON todo_attachments(todo_id);
@@ -0,0 +1,6 @@
+-- Remove the assignee from todos
+
+DROP INDEX IF EXISTS idx_todos_assignee_id;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS assignee_id;
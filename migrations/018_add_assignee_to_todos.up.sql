@@ -0,0 +1,7 @@
+-- Add an assignee to todos
+
+-- The assignee can see and work on the todo alongside its creator
+ALTER TABLE todos
+ADD COLUMN assignee_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_assignee_id ON todos(assignee_id) WHERE assignee_id IS NOT NULL;
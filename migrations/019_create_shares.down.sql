@@ -0,0 +1,4 @@
+-- Drop shares
+
+DROP TRIGGER IF EXISTS update_shares_updated_at ON shares;
+DROP TABLE IF EXISTS shares;
@@ -0,0 +1,25 @@
+-- Create shares for giving other users access to todos and projects
+
+-- A share covers either a single todo or every todo filed directly in a project
+CREATE TABLE IF NOT EXISTS shares (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT chk_shares_target CHECK ((todo_id IS NULL) <> (project_id IS NULL)),
+    CONSTRAINT chk_shares_role CHECK (role IN ('viewer', 'commenter', 'editor'))
+);
+
+-- A user holds at most one role per todo and per project
+CREATE UNIQUE INDEX idx_shares_todo_user ON shares(todo_id, user_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_shares_project_user ON shares(project_id, user_id) WHERE project_id IS NOT NULL;
+CREATE INDEX idx_shares_user_id ON shares(user_id);
+
+CREATE TRIGGER update_shares_updated_at
+    BEFORE UPDATE ON shares
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop share links
+
+DROP TABLE IF EXISTS share_links;
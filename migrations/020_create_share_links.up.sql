@@ -0,0 +1,19 @@
+-- Create share links for read-only access to todos and projects without an account
+
+-- Only a SHA-256 hash of the token is stored; the token itself is shown once, when the link is created
+CREATE TABLE IF NOT EXISTS share_links (
+    id SERIAL PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255),
+    expires_at TIMESTAMP,
+    view_count BIGINT DEFAULT 0 NOT NULL,
+    last_viewed_at TIMESTAMP,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT chk_share_links_target CHECK ((todo_id IS NULL) <> (project_id IS NULL))
+);
+
+CREATE INDEX idx_share_links_todo_id ON share_links(todo_id) WHERE todo_id IS NOT NULL;
+CREATE INDEX idx_share_links_project_id ON share_links(project_id) WHERE project_id IS NOT NULL;
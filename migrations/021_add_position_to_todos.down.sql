@@ -0,0 +1,6 @@
+-- Remove the manual position from todos
+
+DROP INDEX IF EXISTS idx_todos_project_parent_position;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS position;
@@ -0,0 +1,26 @@
+-- Add a manual position to todos
+
+-- position is a fractional index: keys compare as plain byte strings, hence the "C" collation,
+-- and a new key always fits between two others, so moving a todo rewrites only its own row
+-- TEXT rather than a bounded VARCHAR: keys grow with inserts until their list is rebalanced
+ALTER TABLE todos
+ADD COLUMN position TEXT COLLATE "C";
+
+-- Number existing todos in creation order within their list: a project's todos at one
+-- subtask level, or a user's inbox. Trailing zeros are trimmed as keys never end in '0'
+UPDATE todos t
+SET position = k.position
+FROM (
+    SELECT id, RTRIM(LPAD(ROW_NUMBER() OVER (
+        PARTITION BY project_id, parent_id, CASE WHEN project_id IS NULL THEN created_by END
+        ORDER BY created_at, id
+    )::TEXT, 10, '0'), '0') AS position
+    FROM todos
+) k
+WHERE t.id = k.id;
+
+ALTER TABLE todos
+ALTER COLUMN position SET NOT NULL;
+
+-- Supports the position sort mode within a list
+CREATE INDEX idx_todos_project_parent_position ON todos(project_id, parent_id, position);
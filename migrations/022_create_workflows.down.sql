@@ -0,0 +1,10 @@
+-- Drop workflows and remove the status from todos
+
+DROP INDEX IF EXISTS idx_todos_status_id;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS status_id;
+
+DROP TABLE IF EXISTS workflow_transitions;
+DROP TRIGGER IF EXISTS update_workflow_states_updated_at ON workflow_states;
+DROP TABLE IF EXISTS workflow_states;
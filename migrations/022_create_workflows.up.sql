@@ -0,0 +1,36 @@
+-- Create per-project workflow states and add a status to todos
+
+-- A project with states has its todos move through them; todos in a terminal state are completed
+-- Names are unique per project; the constraint is deferred so a workflow can swap names in one go
+CREATE TABLE IF NOT EXISTS workflow_states (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    terminal BOOLEAN DEFAULT FALSE NOT NULL,
+    wip_limit INTEGER,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT uq_workflow_states_project_name UNIQUE (project_id, name) DEFERRABLE INITIALLY DEFERRED,
+    CONSTRAINT chk_workflow_states_wip_limit CHECK (wip_limit IS NULL OR wip_limit > 0)
+);
+
+CREATE TRIGGER update_workflow_states_updated_at
+    BEFORE UPDATE ON workflow_states
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- The moves allowed between states; a project without any allows every move
+CREATE TABLE IF NOT EXISTS workflow_transitions (
+    from_state_id INTEGER NOT NULL REFERENCES workflow_states(id) ON DELETE CASCADE,
+    to_state_id INTEGER NOT NULL REFERENCES workflow_states(id) ON DELETE CASCADE,
+    PRIMARY KEY (from_state_id, to_state_id),
+    CONSTRAINT chk_workflow_transitions_distinct CHECK (from_state_id <> to_state_id)
+);
+
+-- NULL for todos outside a project with a workflow; the application moves todos
+-- of a removed state to another one
+ALTER TABLE todos
+ADD COLUMN status_id INTEGER REFERENCES workflow_states(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_status_id ON todos(status_id) WHERE status_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Drop time entries
+
+DROP TABLE IF EXISTS time_entries;
@@ -0,0 +1,26 @@
+-- Create time entries recording who worked on a todo and when
+
+-- TIMESTAMPTZ so instants stay unambiguous across users in different timezones
+-- A running timer is an entry without ended_at
+CREATE TABLE IF NOT EXISTS time_entries (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    note VARCHAR(500) DEFAULT '' NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CONSTRAINT chk_time_entries_range CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE TRIGGER update_time_entries_updated_at
+    BEFORE UPDATE ON time_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Each user has at most one running timer
+CREATE UNIQUE INDEX idx_time_entries_running ON time_entries(user_id) WHERE ended_at IS NULL;
+
+CREATE INDEX idx_time_entries_user_started_at ON time_entries(user_id, started_at);
+CREATE INDEX idx_time_entries_todo_started_at ON time_entries(todo_id, started_at);